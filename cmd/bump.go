@@ -0,0 +1,202 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/Traackr/binnacle/config"
+	"github.com/spf13/cobra"
+)
+
+// Targets of the bump command, besides an exact version
+const (
+	bumpLatest = "latest"
+	bumpMinor  = "minor"
+	bumpPatch  = "patch"
+)
+
+var bumpRelease string
+var bumpTo string
+
+// bumpCmd represents the bump command
+var bumpCmd = &cobra.Command{
+	Use:   "bump",
+	Short: "Updates the chart version of releases in the config file.",
+	Long: `Updates the version field of each release, or of the release given with --release, in the config
+file.  --to selects the newest version overall (latest), the newest version with the same major version
+(minor), the newest version with the same major and minor versions (patch), or an exact version.  The rest
+of the config file, including its comments, is left untouched.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		bumpCmdPreRun()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return bumpCmdRun(args...)
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		bumpCmdPostRun()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(bumpCmd)
+
+	bumpCmd.Flags().StringVar(&bumpRelease, "release", "", "Only bump the given release, as namespace/release.")
+	bumpCmd.Flags().StringVar(&bumpTo, "to", bumpLatest, "Version to bump to, one of: latest, minor, patch, or an exact version.")
+}
+
+func bumpCmdPreRun() {
+	log.Debug("Executing `bump` command.")
+}
+
+func bumpCmdRun(args ...string) error {
+	exact := bumpTo != bumpLatest && bumpTo != bumpMinor && bumpTo != bumpPatch
+	if exact {
+		if _, err := semver.StrictNewVersion(bumpTo); err != nil {
+			return fmt.Errorf("checking bump flags: --to must be latest, minor, patch or an exact version: %w", err)
+		}
+
+		if len(bumpRelease) == 0 {
+			return fmt.Errorf("checking bump flags: an exact version requires --release")
+		}
+	}
+
+	// Load our configuration
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	doc, err := config.ReadDocument(c.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	charts := c.Charts
+	if len(bumpRelease) > 0 {
+		chart, err := c.FindChart(bumpRelease)
+		if err != nil {
+			return err
+		}
+		charts = []config.ChartConfig{*chart}
+	}
+
+	// Only refresh the repository indexes, so that bump never changes the helm repositories
+	if !exact {
+		if err := refreshRepositories(c); err != nil {
+			return err
+		}
+	}
+
+	var bumped int
+	var indexes = make(map[string]*RepoIndex)
+
+	for _, chart := range charts {
+		target := bumpTo
+
+		if !exact {
+			target, err = bumpTarget(c, chart, indexes)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(target) == 0 || target == chart.Version {
+			continue
+		}
+
+		node, err := doc.Chart(chart.Namespace + "/" + chart.Release)
+		if err != nil {
+			return err
+		}
+
+		if err := doc.SetString(node, []string{"version"}, target); err != nil {
+			return fmt.Errorf("bumping release %s/%s: %w", chart.Namespace, chart.Release, err)
+		}
+
+		from := chart.Version
+		if len(from) == 0 {
+			from = "latest"
+		}
+		fmt.Printf("Bumped %s/%s from %s to %s\n", chart.Namespace, chart.Release, from, target)
+		bumped++
+	}
+
+	if bumped == 0 {
+		fmt.Println("All releases are up to date.")
+		return nil
+	}
+
+	return writeDocument(c.ConfigFile, doc)
+}
+
+func bumpCmdPostRun() {
+	log.Debug("Execution of the `bump` command has completed.")
+}
+
+// bumpTarget returns the version the chart is bumped to, or an empty string if the chart is skipped.  Charts
+// without a repository or registry have no versions to bump to, and charts with a version constraint or no
+// version can only be pinned to the latest version.
+func bumpTarget(c *config.BinnacleConfig, chart config.ChartConfig, indexes map[string]*RepoIndex) (string, error) {
+	var versions []IndexEntry
+
+	if chart.State != config.StatePresent {
+		return "", nil
+	}
+
+	if (len(chart.Version) == 0 || chart.IsVersionRange()) && bumpTo != bumpLatest {
+		log.Infof("Skipping release %s/%s: it has no exact version to bump from", chart.Namespace, chart.Release)
+		return "", nil
+	}
+
+	switch {
+	case chart.IsOCI():
+		var err error
+		versions, err = ociChartVersions(c, chart)
+		if err != nil {
+			return "", err
+		}
+	case len(chart.Repo) > 0:
+		index, err := cachedRepoIndex(indexes, chart.Repo)
+		if err != nil {
+			return "", err
+		}
+		versions = index.Versions(chart.Name)
+	default:
+		log.Debugf("Skipping release %s/%s: only charts from a repository or registry can be bumped", chart.Namespace, chart.Release)
+		return "", nil
+	}
+
+	release, err := outdatedRelease(chart, &LockFile{}, versions)
+	if err != nil {
+		return "", err
+	}
+
+	switch bumpTo {
+	case bumpMinor:
+		return release.Minor.Version, nil
+	case bumpPatch:
+		return release.Patch.Version, nil
+	default:
+		return release.Major.Version, nil
+	}
+}
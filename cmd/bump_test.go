@@ -0,0 +1,114 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestBumpCmdRun_ExactVersion(t *testing.T) {
+	source, err := os.ReadFile("../testdata/demo.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configFile := filepath.Join(t.TempDir(), "demo.yml")
+	if err := os.WriteFile(configFile, source, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.SetConfigFile(configFile)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	bumpRelease = "apps/apps-concourse"
+	bumpTo = "1.4.0"
+	defer func() {
+		bumpRelease = ""
+		bumpTo = bumpLatest
+	}()
+
+	if err := bumpCmdRun(); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	got, _ := os.ReadFile(configFile)
+	want := strings.Replace(string(source), "version: 1.3.1", "version: 1.4.0", 1)
+	if string(got) != want {
+		t.Errorf("want only the version to change, got:\n%s", got)
+	}
+}
+
+func TestBumpCmdRun_ExactVersionRequiresRelease(t *testing.T) {
+	bumpTo = "1.4.0"
+	defer func() { bumpTo = bumpLatest }()
+
+	if err := bumpCmdRun(); err == nil {
+		t.Errorf("want an error for an exact version without --release, but was nil")
+	}
+}
+
+func TestBumpCmdRun_OnlyRefreshesRepositories(t *testing.T) {
+	configFile, source := useConfigCopy(t, "../testdata/demo.yml")
+
+	// The stable repository was added with another URL, which sync would update
+	dir := t.TempDir()
+	repositories := filepath.Join(dir, "repositories.yaml")
+	if err := os.WriteFile(repositories, []byte("repositories:\n- name: stable\n  url: https://charts.example.com/stable\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	index, err := os.ReadFile("../testdata/repo-index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "stable-index.yaml"), index, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := useFakeHelm(t, `case "$2" in
+HELM_REPOSITORY_CONFIG) echo `+repositories+` ;;
+HELM_REPOSITORY_CACHE) echo `+dir+` ;;
+esac`)
+
+	bumpTo = bumpPatch
+	defer func() { bumpTo = bumpLatest }()
+
+	if err := bumpCmdRun(); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	for _, call := range helmCalls(t, calls) {
+		if strings.HasPrefix(call, "repo add") || strings.HasPrefix(call, "repo remove") {
+			t.Errorf("want bump to leave the helm repositories untouched, got helm %s", call)
+		}
+	}
+
+	got, _ := os.ReadFile(configFile)
+	if string(got) == source {
+		t.Errorf("want the version to be bumped, got:\n%s", got)
+	}
+}
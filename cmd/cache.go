@@ -0,0 +1,65 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cacheDirEnv is the environment variable that overrides the location of the binnacle cache
+const cacheDirEnv = "BINNACLE_CACHE_DIR"
+
+// binnacleCacheDir returns the directory binnacle caches data in
+func binnacleCacheDir() (string, error) {
+	if dir := os.Getenv(cacheDirEnv); len(dir) > 0 {
+		return dir, nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locating cache directory: %w", err)
+	}
+
+	return filepath.Join(dir, "binnacle"), nil
+}
+
+// configCacheDir returns the cache directory dedicated to the given config file
+func configCacheDir(configFile string) (string, error) {
+	cacheDir, err := binnacleCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	abs, err := filepath.Abs(configFile)
+	if err != nil {
+		return "", fmt.Errorf("locating cache directory: %w", err)
+	}
+
+	// Keep the name of the config file for readability, and use a hash of its path for uniqueness
+	name := strings.TrimSuffix(filepath.Base(abs), filepath.Ext(abs))
+	sum := sha256.Sum256([]byte(abs))
+
+	return filepath.Join(cacheDir, "configs", fmt.Sprintf("%s-%x", name, sum[:6])), nil
+}
@@ -0,0 +1,50 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigCacheDir(t *testing.T) {
+	t.Setenv(cacheDirEnv, "/tmp/binnacle-cache")
+
+	prod, err := configCacheDir("deploy/prod.yml")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if !strings.HasPrefix(prod, filepath.Join("/tmp/binnacle-cache", "configs", "prod-")) {
+		t.Errorf("want cache directory to be named after the config file, got %s", prod)
+	}
+
+	other, _ := configCacheDir("other/prod.yml")
+	if prod == other {
+		t.Errorf("want config files with the same name to use different cache directories, got %s", prod)
+	}
+
+	again, _ := configCacheDir("deploy/prod.yml")
+	if prod != again {
+		t.Errorf("want the same config file to use the same cache directory, got %s and %s", prod, again)
+	}
+}
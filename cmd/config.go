@@ -0,0 +1,319 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Traackr/binnacle/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var configViewOutput string
+
+// chartStringFields are the chart fields that are always written as strings, even when they look like numbers
+var chartStringFields = map[string]bool{
+	"name":      true,
+	"namespace": true,
+	"release":   true,
+	"repo":      true,
+	"sha256":    true,
+	"state":     true,
+	"url":       true,
+	"version":   true,
+}
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Views or edits the given Binnacle configuration.",
+	Long: `Views or edits the given Binnacle configuration.  Edits are made in place, keeping its comments and
+formatting.  The edited configuration is validated before it is written, so an invalid edit leaves the file
+untouched.`,
+}
+
+var configViewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Prints the effective configuration, after defaults have been applied, as the other commands see it.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return configViewCmdRun()
+	},
+}
+
+var configChartCmd = &cobra.Command{
+	Use:   "chart",
+	Short: "Adds, removes or changes charts.",
+}
+
+var configChartAddCmd = &cobra.Command{
+	Use:   "add NAMESPACE/RELEASE [PATH=VALUE...]",
+	Short: "Adds a chart for the given release, with the given fields.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return configChartAddCmdRun(args[0], args[1:]...)
+	},
+}
+
+var configChartRemoveCmd = &cobra.Command{
+	Use:   "remove NAMESPACE/RELEASE",
+	Short: "Removes the chart of the given release.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return configChartRemoveCmdRun(args[0])
+	},
+}
+
+var configChartSetCmd = &cobra.Command{
+	Use:   "set NAMESPACE/RELEASE PATH=VALUE...",
+	Short: "Sets fields of the chart of the given release, such as values.image.tag=1.4.2 or state=absent.",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return configChartSetCmdRun(args[0], args[1:]...)
+	},
+}
+
+var configRepoCmd = &cobra.Command{
+	Use:   "repo",
+	Short: "Adds or removes repositories.",
+}
+
+var configRepoAddCmd = &cobra.Command{
+	Use:   "add NAME URL",
+	Short: "Adds a repository.",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return configRepoAddCmdRun(args[0], args[1])
+	},
+}
+
+var configRepoRemoveCmd = &cobra.Command{
+	Use:   "remove NAME",
+	Short: "Removes a repository.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return configRepoRemoveCmdRun(args[0])
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(configCmd)
+
+	configCmd.AddCommand(configViewCmd)
+	configViewCmd.Flags().StringVarP(&configViewOutput, "output", "o", outputYAML, "Output format, one of: yaml, json.")
+
+	configCmd.AddCommand(configChartCmd)
+	configChartCmd.AddCommand(configChartAddCmd)
+	configChartCmd.AddCommand(configChartRemoveCmd)
+	configChartCmd.AddCommand(configChartSetCmd)
+
+	configCmd.AddCommand(configRepoCmd)
+	configRepoCmd.AddCommand(configRepoAddCmd)
+	configRepoCmd.AddCommand(configRepoRemoveCmd)
+}
+
+func configViewCmdRun() error {
+	if configViewOutput != outputYAML && configViewOutput != outputJSON {
+		return fmt.Errorf("checking config view flags: unsupported output format %q", configViewOutput)
+	}
+
+	// Load our configuration
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	return writeConfigView(os.Stdout, configViewOutput, c)
+}
+
+// writeConfigView writes the effective configuration in the given output format
+func writeConfigView(w io.Writer, format string, c *config.BinnacleConfig) error {
+	if format == outputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(c.View()); err != nil {
+			return fmt.Errorf("writing config view: %w", err)
+		}
+		return nil
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.View()); err != nil {
+		return fmt.Errorf("writing config view: %w", err)
+	}
+
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("writing config view: %w", err)
+	}
+
+	return nil
+}
+
+func configChartAddCmdRun(ref string, assignments ...string) error {
+	namespace, release, found := splitReleaseRef(ref)
+	if !found {
+		return fmt.Errorf("adding chart: expected namespace/release, got %q", ref)
+	}
+
+	doc, err := config.ReadDocument(viper.ConfigFileUsed())
+	if err != nil {
+		return err
+	}
+
+	if _, err := doc.Chart(ref); err == nil {
+		return fmt.Errorf("adding chart: release %s already exists", ref)
+	}
+
+	if err := doc.AppendItem("charts", [][2]string{{"namespace", namespace}, {"release", release}}); err != nil {
+		return err
+	}
+
+	if err := setChartFields(doc, ref, assignments); err != nil {
+		return err
+	}
+
+	return writeDocument(viper.ConfigFileUsed(), doc)
+}
+
+func configChartRemoveCmdRun(ref string) error {
+	doc, err := config.ReadDocument(viper.ConfigFileUsed())
+	if err != nil {
+		return err
+	}
+
+	chart, err := doc.Chart(ref)
+	if err != nil {
+		return err
+	}
+
+	if err := doc.RemoveItem(chart); err != nil {
+		return err
+	}
+
+	return writeDocument(viper.ConfigFileUsed(), doc)
+}
+
+func configChartSetCmdRun(ref string, assignments ...string) error {
+	doc, err := config.ReadDocument(viper.ConfigFileUsed())
+	if err != nil {
+		return err
+	}
+
+	if err := setChartFields(doc, ref, assignments); err != nil {
+		return err
+	}
+
+	return writeDocument(viper.ConfigFileUsed(), doc)
+}
+
+func configRepoAddCmdRun(name string, url string) error {
+	doc, err := config.ReadDocument(viper.ConfigFileUsed())
+	if err != nil {
+		return err
+	}
+
+	if _, err := doc.Repository(name); err == nil {
+		return fmt.Errorf("adding repository: repository %s already exists", name)
+	}
+
+	if err := doc.AppendItem("repositories", [][2]string{{"name", name}, {"url", url}}); err != nil {
+		return err
+	}
+
+	return writeDocument(viper.ConfigFileUsed(), doc)
+}
+
+func configRepoRemoveCmdRun(name string) error {
+	doc, err := config.ReadDocument(viper.ConfigFileUsed())
+	if err != nil {
+		return err
+	}
+
+	repo, err := doc.Repository(name)
+	if err != nil {
+		return err
+	}
+
+	if err := doc.RemoveItem(repo); err != nil {
+		return err
+	}
+
+	return writeDocument(viper.ConfigFileUsed(), doc)
+}
+
+// splitReleaseRef splits a namespace/release reference
+func splitReleaseRef(ref string) (string, string, bool) {
+	namespace, release, found := strings.Cut(ref, "/")
+
+	return namespace, release, found && len(namespace) > 0 && len(release) > 0
+}
+
+// setChartFields applies the path=value assignments to the chart of the given release
+func setChartFields(doc *config.Document, ref string, assignments []string) error {
+	for _, assignment := range assignments {
+		path, value, err := config.ParseAssignment(assignment)
+		if err != nil {
+			return fmt.Errorf("setting chart field: %w", err)
+		}
+
+		// The document is parsed again after each edit, so the chart is looked up every time
+		chart, err := doc.Chart(ref)
+		if err != nil {
+			return err
+		}
+
+		if len(path) == 1 && chartStringFields[path[0]] {
+			err = doc.SetString(chart, path, value)
+		} else {
+			err = doc.Set(chart, path, value)
+		}
+		if err != nil {
+			return fmt.Errorf("setting chart field of release %s: %w", ref, err)
+		}
+	}
+
+	return nil
+}
+
+// writeDocument validates the edited configuration with the config loader, and writes it to the config file
+func writeDocument(configFile string, doc *config.Document) error {
+	if _, err := config.LoadAndValidate(configFile, doc.Bytes()); err != nil {
+		return fmt.Errorf("validating edited config file, which was not written: %w", err)
+	}
+
+	info, err := os.Stat(configFile)
+	if err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+
+	if err := os.WriteFile(configFile, doc.Bytes(), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// useConfigCopy copies the given config file to a temporary directory and points viper at the copy
+func useConfigCopy(t *testing.T, source string) (string, string) {
+	t.Helper()
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configFile := filepath.Join(t.TempDir(), filepath.Base(source))
+	if err := os.WriteFile(configFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.SetConfigFile(configFile)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	return configFile, string(data)
+}
+
+func TestConfigChartSetCmdRun(t *testing.T) {
+	configFile, source := useConfigCopy(t, "../testdata/demo.yml")
+
+	if err := configChartSetCmdRun("apps/apps-concourse", "values.imageTag=3.11.0", "state=absent", "version=1.10"); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := strings.Replace(source, `imageTag: "3.10.0"`, `imageTag: "3.11.0"`, 1)
+	want = strings.Replace(want, "state: present\n    # Any data", "state: absent\n    # Any data", 1)
+	want = strings.Replace(want, "version: 1.3.1", `version: "1.10"`, 1)
+
+	got, _ := os.ReadFile(configFile)
+	if string(got) != want {
+		t.Errorf("want only the set fields to change, got:\n%s", got)
+	}
+}
+
+func TestConfigChartAddAndRemoveCmdRun(t *testing.T) {
+	configFile, source := useConfigCopy(t, "../testdata/demo.yml")
+
+	if err := configChartAddCmdRun("apps/api", "repo=stable", "name=api", "version=2.0.0", "values.replicas=3"); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	got, _ := os.ReadFile(configFile)
+	added := `  - namespace: apps
+    release: api
+    repo: stable
+    name: api
+    version: 2.0.0
+    values:
+      replicas: 3
+`
+	if !strings.Contains(string(got), "    version: 1.3.1\n"+added+"\n# repositories") {
+		t.Errorf("want the chart added after the last chart, got:\n%s", got)
+	}
+
+	if err := configChartAddCmdRun("apps/api"); err == nil {
+		t.Errorf("want an error for an existing release, but was nil")
+	}
+
+	if err := configChartRemoveCmdRun("apps/api"); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	got, _ = os.ReadFile(configFile)
+	if string(got) != source {
+		t.Errorf("want the original config after removing the added chart, got:\n%s", got)
+	}
+}
+
+func TestConfigRepoAddAndRemoveCmdRun(t *testing.T) {
+	configFile, source := useConfigCopy(t, "../testdata/demo.yml")
+
+	if err := configRepoAddCmdRun("internal", "https://charts.example.com"); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	got, _ := os.ReadFile(configFile)
+	if string(got) != source+"  - name: internal\n    url: https://charts.example.com\n" {
+		t.Errorf("want the repository appended, got:\n%s", got)
+	}
+
+	if err := configRepoRemoveCmdRun("stable"); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	got, _ = os.ReadFile(configFile)
+	if strings.Contains(string(got), "kubernetes-charts") || !strings.Contains(string(got), "repositories:\n  - name: internal\n") {
+		t.Errorf("want the stable repository and its comments removed, got:\n%s", got)
+	}
+}
+
+func TestConfigChartSetCmdRun_InvalidEdit(t *testing.T) {
+	configFile, source := useConfigCopy(t, "../testdata/demo.yml")
+
+	if err := configChartSetCmdRun("apps/apps-concourse", "version=not a version"); err == nil {
+		t.Errorf("want an error for an invalid version, but was nil")
+	}
+
+	got, _ := os.ReadFile(configFile)
+	if string(got) != source {
+		t.Errorf("want the config file untouched after an invalid edit, got:\n%s", got)
+	}
+}
+
+func TestConfigChartSetCmdRun_LoadsSetValues(t *testing.T) {
+	useConfigCopy(t, "../testdata/demo.yml")
+
+	if err := configChartSetCmdRun("apps/apps-concourse", "values.mode=on", "values.port=0755", "values.newTag=1.20", "values.replicas=3", "values.debug=false"); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	c, err := loadConfig()
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	// The values load with the same types helm's --set gives them
+	want := map[string]any{"mode": "on", "port": "0755", "newTag": "1.20", "replicas": 3, "debug": false}
+	for key, value := range want {
+		if got := c.Charts[0].Values[key]; got != value {
+			t.Errorf("want %s to load as %#v, got %#v", key, value, got)
+		}
+	}
+}
+
+func TestConfigChartCmdRun_InvalidConfig(t *testing.T) {
+	configFile, source := useConfigCopy(t, "../testdata/demo.yml")
+
+	if err := configChartSetCmdRun("apps/apps-concourse", "state=absnet"); err == nil {
+		t.Errorf("want an error for an unknown state, but was nil")
+	}
+
+	if err := configChartAddCmdRun("apps/api", "repo=stable", "version=2.0.0"); err == nil {
+		t.Errorf("want an error for a chart without a name, but was nil")
+	}
+
+	got, _ := os.ReadFile(configFile)
+	if string(got) != source {
+		t.Errorf("want the config file untouched after invalid edits, got:\n%s", got)
+	}
+}
+
+func TestWriteConfigView_JSON(t *testing.T) {
+	useConfigCopy(t, "../testdata/demo.yml")
+
+	c, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := writeConfigView(&out, outputJSON, c); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	var view map[string]any
+	if err := json.Unmarshal(out.Bytes(), &view); err != nil {
+		t.Fatalf("want valid json, got %v:\n%s", err, out.String())
+	}
+
+	charts := view["charts"].([]any)
+	if release := charts[0].(map[string]any)["release"]; release != "apps-concourse" {
+		t.Errorf("want release apps-concourse, got %v", release)
+	}
+}
@@ -29,10 +29,19 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// diffChangesExitCode is the exit code used with --detailed-exitcode when changes were detected
+const diffChangesExitCode = 2
+
+var diffDetailedExitCode bool
+var diffNoColor bool
+var diffOutput string
+var diffShowSecrets bool
+var diffUseHelmDiff bool
+
 // diffCmd represents the diff command
 var diffCmd = &cobra.Command{
 	Use:   "diff",
-	Short: "Displays a diff between the current release and new release of a Helm chart.  (Requires helm-diff plugin)",
+	Short: "Displays a diff between the current release and new release of a Helm chart.",
 	Long:  ``,
 	PreRun: func(cmd *cobra.Command, args []string) {
 		diffCmdPreRun()
@@ -47,6 +56,15 @@ var diffCmd = &cobra.Command{
 
 func init() {
 	RootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().BoolVar(&diffDetailedExitCode, "detailed-exitcode", false, "Exit with code 2 when changes are detected.")
+	diffCmd.Flags().BoolVar(&diffNoColor, "no-color", false, "Disable colored diff output.")
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", outputText, "The format of the diff output. Acceptable values: text, markdown, html.")
+	diffCmd.Flags().BoolVar(&diffShowSecrets, "show-secrets", false, "Show the data of Secrets and other sensitive resources instead of redacting it.")
+	diffCmd.Flags().BoolVar(&diffUseHelmDiff, "helm-diff", false, "Use the helm-diff plugin instead of the built-in manifest diff. (Requires helm-diff plugin)")
+
+	addValueOverrideFlags(diffCmd)
+	addUpdateFlag(diffCmd)
 }
 
 func diffCmdPreRun() {
@@ -56,95 +74,281 @@ func diffCmdPreRun() {
 func diffCmdRun(args ...string) error {
 	var err error
 
-	// Detect if the diff plugin is installed.
-	pluginInstalled, err := PluginInstalled("diff")
-	if err != nil {
-		return fmt.Errorf("detecting if helm-diff plugin is installed: %w", err)
+	if !validReportFormat(diffOutput) {
+		return fmt.Errorf("checking diff output format: unsupported format %q", diffOutput)
 	}
 
-	if !pluginInstalled {
-		return fmt.Errorf("checking for helm-diff plugin: helm-diff plugin is required, Please see: https://github.com/databus23/helm-diff")
+	if diffUseHelmDiff && diffOutput != outputText {
+		return fmt.Errorf("checking diff output format: only text output is supported with the helm-diff plugin")
+	}
+
+	if diffUseHelmDiff {
+		// Detect if the diff plugin is installed.
+		pluginInstalled, err := PluginInstalled("diff")
+		if err != nil {
+			return fmt.Errorf("detecting if helm-diff plugin is installed: %w", err)
+		}
+
+		if !pluginInstalled {
+			return fmt.Errorf("checking for helm-diff plugin: helm-diff plugin is required, Please see: https://github.com/databus23/helm-diff")
+		}
 	}
 
 	// Load our configuration
-	c, err := config.LoadAndValidateFromViper()
+	c, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
+	if err := applyValueOverrides(c); err != nil {
+		return err
+	}
+
 	// Sync repositories
-	if err := syncRepositories(c.Repositories, args...); err != nil {
+	plan, err := syncRepositories(c)
+	if err != nil {
 		return err
 	}
 
+	// Resolve chart versions
+	if err := resolveChartVersions(c, updateVersions); err != nil {
+		return err
+	}
+
+	// Fetch charts from other sources
+	if err := fetchCharts(c); err != nil {
+		return err
+	}
+
+	if diffOutput == outputText && !plan.Empty() {
+		printRepositoryPlan(plan)
+	}
+
 	var charts = c.Charts
+	var changes = false
+	var releases []ReleaseDiff
 
 	log.Debugf("Loaded %d charts.", len(charts))
 
 	// Iterate the charts in the config
 	for _, chart := range charts {
-		var cmdArgs []string
-		var res Result
-
 		log.Debugf("Processing chart: %s", chart.ChartURL())
 
-		// Create a temp working directory
-		dir, err := SetupBinnacleWorkingDir()
+		if diffUseHelmDiff {
+			if len(c.DiffIgnore) > 0 || len(chart.DiffIgnore) > 0 {
+				log.Warnf("The diffIgnore rules for release %s/%s are not supported with the helm-diff plugin.", chart.Namespace, chart.Release)
+			}
+
+			changed, err := diffChartWithPlugin(chart, c.ConfigFile, args...)
+			if err != nil {
+				return err
+			}
+
+			changes = changes || changed
+			continue
+		}
+
+		diffs, err := diffChart(c, chart, args...)
 		if err != nil {
 			return err
 		}
-		defer os.RemoveAll(dir)
 
-		//
-		// Template out the charts values
-		//
-		valuesFile, err := chart.WriteValueFile(dir)
-		if err != nil {
+		release := ReleaseDiff{Namespace: chart.Namespace, Release: chart.Release, Diffs: diffs}
+		if diffOutput == outputText {
+			printTextDiff(release)
+		}
+		releases = append(releases, release)
+
+		changes = changes || len(diffs) > 0
+	}
+
+	if diffOutput != outputText {
+		if err := writeDiffReport(os.Stdout, diffOutput, DiffReport{Repositories: plan.Actions, Releases: releases}); err != nil {
 			return err
 		}
+	}
 
-		cmdArgs = append(cmdArgs, "diff")
-		cmdArgs = append(cmdArgs, "upgrade")
-		cmdArgs = append(cmdArgs, chart.Release)
-		cmdArgs = append(cmdArgs, chart.ChartURL())
-		cmdArgs = append(cmdArgs, "--color")
-		cmdArgs = append(cmdArgs, "--normalize-manifests")
-		cmdArgs = append(cmdArgs, "--install")
-		cmdArgs = append(cmdArgs, "--three-way-merge")
-		cmdArgs = append(cmdArgs, "--values")
-		cmdArgs = append(cmdArgs, valuesFile)
-
-		if len(chart.Namespace) > 0 {
-			cmdArgs = append(cmdArgs, "--namespace")
-			cmdArgs = append(cmdArgs, chart.Namespace)
+	if changes && diffDetailedExitCode {
+		return &Result{ExitCode: diffChangesExitCode}
+	}
+
+	return nil
+}
+
+func diffCmdPostRun() {
+	log.Debug("Execution of the `diff` command has completed.")
+}
+
+// diffChart compares the manifest of the deployed release with the output of `helm template` for the chart,
+// after stripping everything matched by the global and chart specific ignore rules
+func diffChart(c *config.BinnacleConfig, chart config.ChartConfig, args ...string) ([]ResourceDiff, error) {
+	var current, desired []Resource
+
+	exists, err := chartReleaseExists(chart, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		manifest, err := getReleaseManifest(chart, args...)
+		if err != nil {
+			return nil, err
 		}
 
-		if len(chart.Version) > 0 {
-			cmdArgs = append(cmdArgs, "--version")
-			cmdArgs = append(cmdArgs, chart.Version)
+		current, err = ParseManifest(manifest)
+		if err != nil {
+			return nil, fmt.Errorf("reading deployed manifest for release %s: %w", chart.Release, err)
 		}
+	}
 
-		if !chart.Kustomize.Empty() {
-			postRenderExecutable, err := SetupKustomize(dir, c.ConfigFile, chart)
-			if err != nil {
-				return err
-			}
-			cmdArgs = append(cmdArgs, "--post-renderer")
-			cmdArgs = append(cmdArgs, postRenderExecutable)
+	// Releases that are absent will have all of their resources removed
+	if chart.State == config.StatePresent {
+		manifest, err := renderChart(chart, c.ConfigFile, args...)
+		if err != nil {
+			return nil, err
 		}
 
-		cmdArgs = append(cmdArgs, args...)
-		res, err = RunHelmCommand(cmdArgs...)
+		desired, err = ParseManifest(manifest)
 		if err != nil {
-			return fmt.Errorf("running helm diff for release %s: %s: %w", chart.Release, res.Stderr, err)
+			return nil, fmt.Errorf("reading rendered manifest for release %s: %w", chart.Release, err)
 		}
+	}
+
+	rules := append(append([]config.DiffIgnoreRule{}, c.DiffIgnore...), chart.DiffIgnore...)
+
+	current, err = applyIgnoreRules(rules, current)
+	if err != nil {
+		return nil, err
+	}
 
-		fmt.Println(strings.TrimSpace(res.Stdout))
+	desired, err = applyIgnoreRules(rules, desired)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	if !diffShowSecrets {
+		redactResources(c.SensitiveKinds, current, desired)
+	}
+
+	diffs, err := DiffResources(current, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(diffs) == 0 {
+		log.Debugf("No changes for release %s/%s.", chart.Namespace, chart.Release)
+	}
+
+	return diffs, nil
 }
 
-func diffCmdPostRun() {
-	log.Debug("Execution of the `diff` command has completed.")
+// diffChartWithPlugin runs `helm diff upgrade` for the chart using the helm-diff plugin
+func diffChartWithPlugin(chart config.ChartConfig, configFile string, args ...string) (bool, error) {
+	var cmdArgs []string
+	var res Result
+
+	// Create a temp working directory
+	dir, err := SetupBinnacleWorkingDir()
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(dir)
+
+	//
+	// Template out the charts values
+	//
+	valuesFile, err := chart.WriteValueFile(dir)
+	if err != nil {
+		return false, err
+	}
+
+	cmdArgs = append(cmdArgs, "diff")
+	cmdArgs = append(cmdArgs, "upgrade")
+	cmdArgs = append(cmdArgs, chart.Release)
+	cmdArgs = append(cmdArgs, chart.ChartURL())
+	if !diffNoColor {
+		cmdArgs = append(cmdArgs, "--color")
+	}
+	cmdArgs = append(cmdArgs, "--normalize-manifests")
+	cmdArgs = append(cmdArgs, "--install")
+	cmdArgs = append(cmdArgs, "--three-way-merge")
+	cmdArgs = append(cmdArgs, "--detailed-exitcode")
+	cmdArgs = append(cmdArgs, "--values")
+	cmdArgs = append(cmdArgs, valuesFile)
+
+	if len(chart.Namespace) > 0 {
+		cmdArgs = append(cmdArgs, "--namespace")
+		cmdArgs = append(cmdArgs, chart.Namespace)
+	}
+
+	if len(chart.Version) > 0 {
+		cmdArgs = append(cmdArgs, "--version")
+		cmdArgs = append(cmdArgs, chart.Version)
+	}
+
+	if !chart.Kustomize.Empty() {
+		postRenderExecutable, err := SetupKustomize(dir, configFile, chart)
+		if err != nil {
+			return false, err
+		}
+		cmdArgs = append(cmdArgs, "--post-renderer")
+		cmdArgs = append(cmdArgs, postRenderExecutable)
+	}
+
+	cmdArgs = append(cmdArgs, args...)
+	cmdArgs = append(cmdArgs, chart.HelmArgs("diff")...)
+	res, err = RunHelmCommand(cmdArgs...)
+
+	// helm-diff exits with code 2 when it has detected changes
+	changed := res.ExitCode == diffChangesExitCode
+	if err != nil && !changed {
+		return false, fmt.Errorf("running helm diff for release %s: %s: %w", chart.Release, res.Stderr, err)
+	}
+
+	fmt.Println(strings.TrimSpace(res.Stdout))
+
+	return changed, nil
+}
+
+// getReleaseManifest returns the manifest of the currently deployed release, including its hooks.  `helm get
+// manifest` leaves out hooks, while `helm template` renders them, so without the hooks every hook would show up
+// as added.  The release is read with the chart's `helm status` arguments, such as a kube-context, so that it is
+// read from the same cluster its existence was checked in.
+func getReleaseManifest(chart config.ChartConfig, args ...string) (string, error) {
+	getArgs := append(append([]string{}, args...), chart.HelmArgs("status")...)
+
+	manifest, err := runHelmGet("manifest", chart.Namespace, chart.Release, getArgs...)
+	if err != nil {
+		return "", err
+	}
+
+	hooks, err := runHelmGet("hooks", chart.Namespace, chart.Release, getArgs...)
+	if err != nil {
+		return "", err
+	}
+
+	if len(strings.TrimSpace(hooks)) == 0 {
+		return manifest, nil
+	}
+
+	return manifest + "\n---\n" + hooks, nil
+}
+
+// runHelmGet runs `helm get` for the given part of the deployed release
+func runHelmGet(part string, namespace string, release string, args ...string) (string, error) {
+	var cmdArgs []string
+
+	cmdArgs = append(cmdArgs, "get")
+	cmdArgs = append(cmdArgs, part)
+	cmdArgs = append(cmdArgs, release)
+	cmdArgs = append(cmdArgs, "--namespace")
+	cmdArgs = append(cmdArgs, namespace)
+	cmdArgs = append(cmdArgs, args...)
+
+	res, err := RunHelmCommand(cmdArgs...)
+	if err != nil {
+		return "", fmt.Errorf("running helm get %s for release %s: %s: %w", part, release, res.Stderr, err)
+	}
+
+	return res.Stdout, nil
 }
@@ -0,0 +1,71 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+func TestGetReleaseManifest_IncludesHooks(t *testing.T) {
+	useFakeHelm(t, `case "$1 $2" in
+"get manifest") printf 'kind: Deployment\nmetadata:\n  name: web\n' ;;
+"get hooks") printf 'kind: Job\nmetadata:\n  name: migrate\n' ;;
+esac`)
+
+	manifest, err := getReleaseManifest(config.ChartConfig{Namespace: "apps", Release: "web"})
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	resources, err := ParseManifest(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resources) != 2 || resources[0].Kind != "Deployment" || resources[1].Kind != "Job" {
+		t.Errorf("want the Deployment and the hook Job, got %v", resources)
+	}
+}
+
+func TestGetReleaseManifest_StatusArgs(t *testing.T) {
+	calls := useFakeHelm(t, `printf 'kind: Deployment\nmetadata:\n  name: web\n'`)
+
+	chart := config.ChartConfig{
+		Namespace: "apps",
+		Release:   "web",
+		ExtraArgs: map[string][]string{"status": {"--kube-context", "prod"}},
+	}
+
+	if _, err := getReleaseManifest(chart, "--debug"); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := []string{
+		"get manifest web --namespace apps --debug --kube-context prod",
+		"get hooks web --namespace apps --debug --kube-context prod",
+	}
+	if got := helmCalls(t, calls); !reflect.DeepEqual(got, want) {
+		t.Errorf("want helm calls %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// httpClient is used for all HTTP requests made by binnacle itself, rather than helm
+var httpClient = &http.Client{Timeout: 5 * time.Minute}
+
+// chartCacheDir returns the cache directory the chart at the given url is downloaded to
+func chartCacheDir(chartURL string) (string, error) {
+	cacheDir, err := binnacleCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(chartURL))
+
+	return filepath.Join(cacheDir, "charts", fmt.Sprintf("%x", sum[:8])), nil
+}
+
+// downloadChart downloads the chart at the given url into the cache and returns the path of the downloaded
+// file.  When a sha256 digest is given, the download must match it, and a cached download that matches it is
+// reused.
+func downloadChart(chartURL string, digest string) (string, error) {
+	dir, err := chartCacheDir(chartURL)
+	if err != nil {
+		return "", err
+	}
+
+	name := "chart.tgz"
+	if u, err := url.Parse(chartURL); err == nil && path.Base(u.Path) != "/" && path.Base(u.Path) != "." {
+		name = path.Base(u.Path)
+	}
+	file := filepath.Join(dir, name)
+
+	if len(digest) > 0 {
+		if sum, err := fileSHA256(file); err == nil && strings.EqualFold(sum, digest) {
+			log.Debugf("Using cached chart %s", file)
+			return file, nil
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating chart cache directory: %w", err)
+	}
+
+	log.Debugf("Downloading chart %s", chartURL)
+
+	resp, err := httpClient.Get(chartURL)
+	if err != nil {
+		return "", fmt.Errorf("downloading chart %s: %w", chartURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading chart %s: unexpected status %s", chartURL, resp.Status)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".download-*")
+	if err != nil {
+		return "", fmt.Errorf("downloading chart %s: %w", chartURL, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, hash), resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("downloading chart %s: %w", chartURL, err)
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if len(digest) > 0 && !strings.EqualFold(sum, digest) {
+		return "", fmt.Errorf("verifying chart %s: sha256 digest %s does not match the expected %s", chartURL, sum, digest)
+	}
+
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return "", fmt.Errorf("downloading chart %s: %w", chartURL, err)
+	}
+
+	return file, nil
+}
+
+// fileSHA256 returns the hex sha256 digest of the given file
+func fileSHA256(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,104 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownloadChart(t *testing.T) {
+	t.Setenv(cacheDirEnv, t.TempDir())
+
+	content := []byte("chart archive")
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/charts/api-1.0.0.tgz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	file, err := downloadChart(server.URL+"/charts/api-1.0.0.tgz", digest)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if filepath.Base(file) != "api-1.0.0.tgz" {
+		t.Errorf("want the downloaded file to be named api-1.0.0.tgz, got %s", file)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil || string(data) != string(content) {
+		t.Errorf("want the downloaded chart content, got %q (%v)", data, err)
+	}
+
+	// A cached download matching the digest is reused
+	if _, err := downloadChart(server.URL+"/charts/api-1.0.0.tgz", digest); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("want 1 request with a cached download, got %d", requests)
+	}
+}
+
+func TestDownloadChart_DigestMismatch(t *testing.T) {
+	t.Setenv(cacheDirEnv, t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tampered archive"))
+	}))
+	defer server.Close()
+
+	digest := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	file, err := downloadChart(server.URL+"/api-1.0.0.tgz", digest)
+	if err == nil {
+		t.Fatalf("want an error for a digest mismatch, but was nil")
+	}
+
+	dir, _ := chartCacheDir(server.URL + "/api-1.0.0.tgz")
+	entries, _ := os.ReadDir(dir)
+	if len(file) > 0 || len(entries) > 0 {
+		t.Errorf("want no file left in the cache after a digest mismatch, got %v", entries)
+	}
+}
+
+func TestDownloadChart_NotFound(t *testing.T) {
+	t.Setenv(cacheDirEnv, t.TempDir())
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	if _, err := downloadChart(server.URL+"/missing.tgz", ""); err == nil {
+		t.Errorf("want an error for a missing chart, but was nil")
+	}
+}
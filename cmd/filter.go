@@ -0,0 +1,116 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"path"
+	"strings"
+)
+
+// ResourceFilter selects resources from a rendered manifest.  Resources must match one of the values of
+// every non-empty criterion to be selected.
+type ResourceFilter struct {
+	// Kinds are matched case-insensitively
+	Kinds []string
+	// Names support shell glob patterns
+	Names []string
+	// Sources are the chart templates the resources were rendered from, e.g. templates/deployment.yaml
+	Sources []string
+}
+
+// Empty returns if the filter selects every resource
+func (f ResourceFilter) Empty() bool {
+	return len(f.Kinds) == 0 && len(f.Names) == 0 && len(f.Sources) == 0
+}
+
+// Apply returns the resources selected by the filter
+func (f ResourceFilter) Apply(resources []Resource) []Resource {
+	if f.Empty() {
+		return resources
+	}
+
+	var res []Resource
+	for _, r := range resources {
+		if f.matchesKind(r) && f.matchesName(r) && f.matchesSource(r) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+func (f ResourceFilter) matchesKind(r Resource) bool {
+	if len(f.Kinds) == 0 {
+		return true
+	}
+
+	for _, kind := range f.Kinds {
+		if strings.EqualFold(kind, r.Kind) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f ResourceFilter) matchesName(r Resource) bool {
+	if len(f.Names) == 0 {
+		return true
+	}
+
+	for _, name := range f.Names {
+		if ok, _ := path.Match(name, r.Name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (f ResourceFilter) matchesSource(r Resource) bool {
+	if len(f.Sources) == 0 {
+		return true
+	}
+
+	// Helm prefixes the source with the name of the chart
+	for _, source := range f.Sources {
+		source = strings.TrimPrefix(source, "./")
+		if r.Source == source || strings.HasSuffix(r.Source, "/"+source) {
+			return true
+		}
+	}
+	return false
+}
+
+// assignSources sets the source of resources whose `# Source:` comment is missing, such as resources that
+// went through the kustomize post-renderer, to the source of the resource with the same kind and name in the
+// chart's own rendered manifest
+func assignSources(resources []Resource, rendered []Resource) []Resource {
+	sources := make(map[string]string)
+	for _, r := range rendered {
+		sources[r.Kind+"/"+r.Name] = r.Source
+	}
+
+	for idx := range resources {
+		if len(resources[idx].Source) == 0 {
+			resources[idx].Source = sources[resources[idx].Kind+"/"+resources[idx].Name]
+		}
+	}
+
+	return resources
+}
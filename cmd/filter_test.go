@@ -0,0 +1,85 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestResourceFilter(t *testing.T) {
+	resources, _ := ParseManifest(desiredManifest)
+
+	tests := []struct {
+		filter ResourceFilter
+		want   int
+	}{
+		{ResourceFilter{}, 3},
+		{ResourceFilter{Kinds: []string{"deployment", "ConfigMap"}}, 2},
+		{ResourceFilter{Kinds: []string{"Deployment"}, Names: []string{"other"}}, 0},
+		{ResourceFilter{Names: []string{"w*"}}, 3},
+		{ResourceFilter{Sources: []string{"templates/service.yaml"}}, 1},
+		{ResourceFilter{Sources: []string{"web/templates/service.yaml", "./templates/configmap.yaml"}}, 2},
+		{ResourceFilter{Sources: []string{"vice.yaml"}}, 0},
+	}
+
+	for _, tt := range tests {
+		got := tt.filter.Apply(resources)
+		if len(got) != tt.want {
+			t.Errorf("want %#v to select %d resources, got %d", tt.filter, tt.want, len(got))
+		}
+	}
+}
+
+func TestResourceFilter_PostRendered(t *testing.T) {
+	rendered, _ := ParseManifest(desiredManifest)
+
+	// kustomize drops the comments, and reorders the resources
+	kustomized, _ := ParseManifest(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+  namespace: apps
+data:
+  replicas: "5"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: apps
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: extra
+`)
+
+	filter := ResourceFilter{Sources: []string{"templates/configmap.yaml"}}
+
+	if got := filter.Apply(kustomized); len(got) != 0 {
+		t.Errorf("want no resources without their source, got %v", got)
+	}
+
+	got := filter.Apply(assignSources(kustomized, rendered))
+	if len(got) != 1 || got[0].Kind != "ConfigMap" || got[0].Name != "web" {
+		t.Errorf("want the post-rendered web ConfigMap, got %v", got)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/Traackr/binnacle/config"
+	"github.com/spf13/cobra"
+)
+
+var fmtCheck bool
+
+// fmtCmd represents the fmt command
+var fmtCmd = &cobra.Command{
+	Use:   "fmt",
+	Short: "Rewrites the config file in its canonical format.",
+	Long: `Rewrites the config file in its canonical format.  Charts are sorted by namespace and release, the keys
+of charts and repositories are put in their documented order, and strings that look like numbers or booleans,
+such as imageTag: 3.10, are quoted.  Comments are kept.  With --check the config file is left untouched and
+the command exits with a non-zero exit code if it is not formatted.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		fmtCmdPreRun()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmtCmdRun(args...)
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		fmtCmdPostRun()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(fmtCmd)
+
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "Only check that the config file is formatted, exiting with a non-zero exit code if it is not.")
+}
+
+func fmtCmdPreRun() {
+	log.Debug("Executing `fmt` command.")
+}
+
+func fmtCmdRun(args ...string) error {
+	// Load our configuration, which makes sure only valid config files are formatted
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(c.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	formatted, err := config.Format(data)
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(data, formatted) {
+		log.Debugf("%s is formatted", c.ConfigFile)
+		return nil
+	}
+
+	if fmtCheck {
+		fmt.Printf("%s is not formatted\n", c.ConfigFile)
+		return &Result{ExitCode: 1}
+	}
+
+	doc, err := config.ParseDocument(formatted)
+	if err != nil {
+		return err
+	}
+
+	if err := writeDocument(c.ConfigFile, doc); err != nil {
+		return err
+	}
+
+	fmt.Printf("Formatted %s\n", c.ConfigFile)
+
+	return nil
+}
+
+func fmtCmdPostRun() {
+	log.Debug("Execution of the `fmt` command has completed.")
+}
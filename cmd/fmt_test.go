@@ -0,0 +1,72 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFmtCmdRun(t *testing.T) {
+	configFile, source := useConfigCopy(t, "../testdata/demo.yml")
+
+	if err := fmtCmdRun(); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	got, _ := os.ReadFile(configFile)
+	if !strings.Contains(string(got), `version: "1.3.1"`) {
+		t.Errorf("want the version to be quoted, got:\n%s", got)
+	}
+
+	if string(got) == source {
+		t.Errorf("want the config file to be formatted, but was unchanged")
+	}
+}
+
+func TestFmtCmdRun_Check(t *testing.T) {
+	configFile, source := useConfigCopy(t, "../testdata/demo.yml")
+
+	fmtCheck = true
+	defer func() { fmtCheck = false }()
+
+	var res *Result
+	if err := fmtCmdRun(); !errors.As(err, &res) || res.ExitCode != 1 {
+		t.Errorf("want exit code 1 for an unformatted config file, got %v", err)
+	}
+
+	got, _ := os.ReadFile(configFile)
+	if string(got) != source {
+		t.Errorf("want --check to leave the config file untouched, got:\n%s", got)
+	}
+
+	fmtCheck = false
+	if err := fmtCmdRun(); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	fmtCheck = true
+	if err := fmtCmdRun(); err != nil {
+		t.Errorf("want no error for a formatted config file, got %v", err)
+	}
+}
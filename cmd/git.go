@@ -0,0 +1,157 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+// gitCacheDir returns the cache directory of the given git repository.  It holds a single clone of the
+// repository, and a worktree for each ref that is checked out.
+func gitCacheDir(url string) (string, error) {
+	cacheDir, err := binnacleCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cacheDir, "git", shortHash(url)), nil
+}
+
+// shortHash returns a short hex digest of the given value, used to name cache directories
+func shortHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+
+	return fmt.Sprintf("%x", sum[:8])
+}
+
+// checkoutGitSource fetches the git repository of the source into the cache, checks out its ref in a worktree
+// of its own, and returns the directory of the chart.  Releases that use different refs of the same repository
+// each get their own worktree, so they never see each other's checkout.
+func checkoutGitSource(source config.GitSource) (string, error) {
+	dir, err := gitCacheDir(source.URL)
+	if err != nil {
+		return "", err
+	}
+
+	repo := filepath.Join(dir, "repo.git")
+	worktree := filepath.Join(dir, "worktrees", shortHash(source.Ref))
+
+	if _, err := os.Stat(repo); os.IsNotExist(err) {
+		if err := os.MkdirAll(repo, 0755); err != nil {
+			return "", fmt.Errorf("creating git cache directory: %w", err)
+		}
+
+		if _, err := runGitCommand(repo, "init", "--quiet", "--bare"); err != nil {
+			return "", err
+		}
+
+		if _, err := runGitCommand(repo, "remote", "add", "origin", source.URL); err != nil {
+			return "", err
+		}
+	}
+
+	log.Debugf("Fetching git repository %s", source.URL)
+
+	if _, err := runGitCommand(repo, "fetch", "--quiet", "--force", "--tags", "origin", "+refs/heads/*:refs/remotes/origin/*"); err != nil {
+		return "", err
+	}
+
+	commit, err := resolveGitRef(repo, source.Ref)
+	if err != nil {
+		return "", fmt.Errorf("checking out %s: %w", source, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(worktree, ".git")); os.IsNotExist(err) {
+		// Forget worktrees whose directory was removed from the cache
+		if _, err := runGitCommand(repo, "worktree", "prune"); err != nil {
+			return "", err
+		}
+
+		if _, err := runGitCommand(repo, "worktree", "add", "--quiet", "--force", "--detach", worktree, commit); err != nil {
+			return "", err
+		}
+
+		return filepath.Join(worktree, source.Path), nil
+	}
+
+	if _, err := runGitCommand(worktree, "checkout", "--quiet", "--force", "--detach", commit); err != nil {
+		return "", err
+	}
+
+	// Remove untracked files left by a previous checkout, but keep ignored files such as built dependencies
+	if _, err := runGitCommand(worktree, "clean", "--quiet", "-ffd"); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(worktree, source.Path), nil
+}
+
+// resolveGitRef returns the commit of the given branch, tag or commit.  Branches are resolved against the
+// fetched remote branches so that they follow the remote.
+func resolveGitRef(dir string, ref string) (string, error) {
+	for _, candidate := range []string{"refs/remotes/origin/" + ref, ref} {
+		res, err := runGitCommand(dir, "rev-parse", "--verify", "--quiet", candidate+"^{commit}")
+		if err == nil {
+			return strings.TrimSpace(res.Stdout), nil
+		}
+	}
+
+	return "", fmt.Errorf("resolving git ref %s: no branch, tag or commit found", ref)
+}
+
+// runGitCommand runs the given git command in the given directory
+func runGitCommand(dir string, args ...string) (Result, error) {
+	var result Result
+	var outbuf, errbuf bytes.Buffer
+
+	git, err := exec.LookPath("git")
+	if err != nil {
+		return result, fmt.Errorf("searching for git on PATH: %w", err)
+	}
+	cmd := exec.Command(git, append([]string{"-C", dir}, args...)...)
+
+	log.Debugf("Executing command:  %v", cmd.Args)
+
+	cmd.Stdout = &outbuf
+	cmd.Stderr = &errbuf
+
+	err = cmd.Run()
+
+	result.Stdout = outbuf.String()
+	result.Stderr = strings.TrimSpace(errbuf.String())
+
+	if err != nil {
+		if exitError, ok := err.(*exec.ExitError); ok {
+			result.ExitCode = exitError.ExitCode()
+		}
+		return result, fmt.Errorf("running git %s: %s: %w", args[0], result.Stderr, err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,176 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+// git runs a git command for the tests and fails the test on errors
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	args = append([]string{"-C", dir, "-c", "user.name=binnacle", "-c", "user.email=binnacle@example.com"}, args...)
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("running git %v: %s: %v", args, out, err)
+	}
+}
+
+// commitChart commits a chart with the given version to the repository in dir
+func commitChart(t *testing.T, dir string, version string) {
+	t.Helper()
+
+	chartDir := filepath.Join(dir, "charts", "demo")
+	if err := os.MkdirAll(chartDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	chart := "apiVersion: v2\nname: demo\nversion: " + version + "\n"
+	if err := os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte(chart), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "--quiet", "-m", "Release "+version)
+}
+
+func TestCheckoutGitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Setenv(cacheDirEnv, t.TempDir())
+
+	// Create a bare repository with a tagged release, followed by a newer commit on main
+	work := t.TempDir()
+	remote := filepath.Join(t.TempDir(), "charts.git")
+
+	git(t, work, "init", "--quiet", "-b", "main")
+	commitChart(t, work, "1.0.0")
+	git(t, work, "tag", "v1.0.0")
+	commitChart(t, work, "1.1.0")
+	git(t, work, "clone", "--quiet", "--bare", work, remote)
+
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"v1.0.0", "1.0.0"},
+		{"main", "1.1.0"},
+		{"v1.0.0", "1.0.0"},
+	}
+
+	for _, test := range tests {
+		source := config.GitSource{URL: "file://" + remote, Ref: test.ref, Path: "charts/demo"}
+
+		dir, err := checkoutGitSource(source)
+		if err != nil {
+			t.Fatalf("want no error checking out %s, got %v", test.ref, err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+		if err != nil {
+			t.Fatalf("want Chart.yaml in %s, got %v", dir, err)
+		}
+
+		want := "apiVersion: v2\nname: demo\nversion: " + test.want + "\n"
+		if string(data) != want {
+			t.Errorf("want chart version %s for ref %s, got %q", test.want, test.ref, data)
+		}
+	}
+
+	// The branch follows new commits on the remote
+	commitChart(t, work, "1.2.0")
+	git(t, work, "push", "--quiet", remote, "main")
+
+	dir, err := checkoutGitSource(config.GitSource{URL: "file://" + remote, Ref: "main", Path: "charts/demo"})
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+	if string(data) != "apiVersion: v2\nname: demo\nversion: 1.2.0\n" {
+		t.Errorf("want chart version 1.2.0 after a push, got %q", data)
+	}
+
+	if _, err := checkoutGitSource(config.GitSource{URL: "file://" + remote, Ref: "missing"}); err == nil {
+		t.Errorf("want an error for a missing ref, but was nil")
+	}
+}
+
+func TestCheckoutGitSource_RefsOfTheSameRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Setenv(cacheDirEnv, t.TempDir())
+
+	work := t.TempDir()
+	remote := filepath.Join(t.TempDir(), "charts.git")
+
+	git(t, work, "init", "--quiet", "-b", "main")
+	commitChart(t, work, "1.0.0")
+	git(t, work, "tag", "v1.0.0")
+	commitChart(t, work, "2.0.0")
+	git(t, work, "clone", "--quiet", "--bare", work, remote)
+
+	// Two releases of the same repository at different refs, checked out one after the other
+	stable, err := checkoutGitSource(config.GitSource{URL: "file://" + remote, Ref: "v1.0.0", Path: "charts/demo"})
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	latest, err := checkoutGitSource(config.GitSource{URL: "file://" + remote, Ref: "main", Path: "charts/demo"})
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if stable == latest {
+		t.Fatalf("want a separate checkout for each ref, got %s for both", stable)
+	}
+
+	tests := map[string]string{stable: "1.0.0", latest: "2.0.0"}
+	for dir, version := range tests {
+		data, err := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+		if err != nil {
+			t.Fatalf("want Chart.yaml in %s, got %v", dir, err)
+		}
+
+		want := "apiVersion: v2\nname: demo\nversion: " + version + "\n"
+		if string(data) != want {
+			t.Errorf("want chart version %s in %s, got %q", version, dir, data)
+		}
+	}
+
+	// A removed worktree is checked out again
+	if err := os.RemoveAll(filepath.Join(stable, "..", "..")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := checkoutGitSource(config.GitSource{URL: "file://" + remote, Ref: "v1.0.0", Path: "charts/demo"}); err != nil {
+		t.Errorf("want no error after removing the worktree, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"strconv"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+// applyIgnoreRules strips the fields matched by the given rules from the resources and drops the resources
+// that are ignored entirely
+func applyIgnoreRules(rules []config.DiffIgnoreRule, resources []Resource) ([]Resource, error) {
+	if len(rules) == 0 {
+		return resources, nil
+	}
+
+	var res []Resource
+
+	for _, r := range resources {
+		ignored := false
+
+		for _, rule := range rules {
+			if !rule.Matches(r.Kind, r.Name) {
+				continue
+			}
+
+			if len(rule.Paths) == 0 {
+				ignored = true
+				break
+			}
+
+			for _, p := range rule.Paths {
+				segments, err := config.ParseFieldPath(p)
+				if err != nil {
+					return nil, err
+				}
+				removeField(r.Object, segments)
+			}
+		}
+
+		if ignored {
+			log.Debugf("Ignoring resource: %s", r)
+			continue
+		}
+
+		res = append(res, r)
+	}
+
+	return res, nil
+}
+
+// removeField removes the field at the given path from a map or list, expanding wildcards along the way
+func removeField(v any, segments []string) any {
+	if len(segments) == 0 {
+		return v
+	}
+
+	key := segments[0]
+	last := len(segments) == 1
+
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if key != config.PathWildcard && key != k {
+				continue
+			}
+
+			if last {
+				delete(v, k)
+			} else {
+				v[k] = removeField(child, segments[1:])
+			}
+		}
+		return v
+	case []any:
+		var res []any
+		for i, child := range v {
+			if key != config.PathWildcard && key != strconv.Itoa(i) {
+				res = append(res, child)
+				continue
+			}
+
+			if !last {
+				res = append(res, removeField(child, segments[1:]))
+			}
+		}
+		return res
+	default:
+		return v
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+func TestApplyIgnoreRules(t *testing.T) {
+	current, _ := ParseManifest(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    helm.sh/chart: web-1.0.0
+spec:
+  template:
+    metadata:
+      annotations:
+        checksum/config: abc
+    spec:
+      containers:
+        - name: web
+          env:
+            - name: RANDOM
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web-tls
+`)
+	desired, _ := ParseManifest(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    helm.sh/chart: web-1.0.1
+spec:
+  template:
+    metadata:
+      annotations:
+        checksum/config: def
+    spec:
+      containers:
+        - name: web
+`)
+
+	rules := []config.DiffIgnoreRule{
+		{Paths: []string{`metadata.labels["helm.sh/chart"]`}},
+		{Kind: "Deployment", Paths: []string{
+			`spec.template.metadata.annotations["checksum/config"]`,
+			"spec.template.spec.containers[*].env",
+		}},
+		{Kind: "Secret", Name: "*-tls"},
+	}
+
+	current, err := applyIgnoreRules(rules, current)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	desired, _ = applyIgnoreRules(rules, desired)
+
+	if len(current) != 1 {
+		t.Errorf("want the Secret to be ignored, got %d resources", len(current))
+	}
+
+	diffs, _ := DiffResources(current, desired)
+	if len(diffs) != 0 {
+		t.Errorf("want no diffs after applying ignore rules, got:\n%s", diffs[0].Diff)
+	}
+}
+
+func TestRemoveField_ListIndex(t *testing.T) {
+	list := []any{"a", "b", "c"}
+
+	got := removeField(map[string]any{"list": list}, []string{"list", "1"}).(map[string]any)["list"].([]any)
+	if len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Errorf("want [a c], got %v", got)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/Traackr/binnacle/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var lintValues bool
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Validates the given Binnacle configuration",
+	Long:  ``,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		lintCmdPreRun()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return lintCmdRun(args...)
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		lintCmdPostRun()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().BoolVar(&lintValues, "values", false, "Check the values of each chart against the default values of the chart.")
+}
+
+func lintCmdPreRun() {
+	log.Debug("Executing `lint` command.")
+}
+
+func lintCmdRun(args ...string) error {
+	// Load our configuration
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if !lintValues {
+		fmt.Println("The configuration is valid.")
+		return nil
+	}
+
+	// Sync repositories
+	if _, err := syncRepositories(c); err != nil {
+		return err
+	}
+
+	// Fetch charts from other sources
+	if err := fetchCharts(c); err != nil {
+		return err
+	}
+
+	var issues int
+
+	for _, chart := range c.Charts {
+		log.Debugf("Processing chart: %s", chart.ChartURL())
+
+		if chart.State != config.StatePresent || len(chart.Values) == 0 {
+			continue
+		}
+
+		defaults, err := getChartDefaultValues(chart, args...)
+		if err != nil {
+			return err
+		}
+
+		// The values of subcharts are checked by the subcharts, not by their parent
+		subcharts, err := getChartSubcharts(chart, args...)
+		if err != nil {
+			return err
+		}
+
+		freeform := append(append([]string{}, chart.FreeformValues...), subcharts...)
+		for _, issue := range config.LintValues(defaults, chart.Values, freeform) {
+			fmt.Printf("%s/%s: values.%s\n", chart.Namespace, chart.Release, issue)
+			issues++
+		}
+	}
+
+	if issues > 0 {
+		return fmt.Errorf("linting chart values: found %d issues", issues)
+	}
+
+	fmt.Println("The configuration is valid.")
+
+	return nil
+}
+
+func lintCmdPostRun() {
+	log.Debug("Execution of the `lint` command has completed.")
+}
+
+// getChartDefaultValues returns the default values of the chart at the configured version
+func getChartDefaultValues(chart config.ChartConfig, args ...string) (map[string]any, error) {
+	var cmdArgs []string
+	var values map[string]any
+
+	cmdArgs = append(cmdArgs, "show")
+	cmdArgs = append(cmdArgs, "values")
+	cmdArgs = append(cmdArgs, chart.ChartURL())
+
+	if len(chart.Version) > 0 {
+		cmdArgs = append(cmdArgs, "--version")
+		cmdArgs = append(cmdArgs, chart.Version)
+	}
+
+	cmdArgs = append(cmdArgs, args...)
+
+	res, err := RunHelmCommand(cmdArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("running helm show values for release %s: %s: %w", chart.Release, res.Stderr, err)
+	}
+
+	if err := yaml.Unmarshal([]byte(res.Stdout), &values); err != nil {
+		return nil, fmt.Errorf("reading default values for release %s: %w", chart.Release, err)
+	}
+
+	return values, nil
+}
+
+// getChartSubcharts returns the keys of the values of the chart's dependencies, which are their alias or name
+func getChartSubcharts(chart config.ChartConfig, args ...string) ([]string, error) {
+	var cmdArgs []string
+	var metadata chartMetadata
+	var subcharts []string
+
+	cmdArgs = append(cmdArgs, "show")
+	cmdArgs = append(cmdArgs, "chart")
+	cmdArgs = append(cmdArgs, chart.ChartURL())
+
+	if len(chart.Version) > 0 {
+		cmdArgs = append(cmdArgs, "--version")
+		cmdArgs = append(cmdArgs, chart.Version)
+	}
+
+	cmdArgs = append(cmdArgs, args...)
+
+	res, err := RunHelmCommand(cmdArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("running helm show chart for release %s: %s: %w", chart.Release, res.Stderr, err)
+	}
+
+	if err := yaml.Unmarshal([]byte(res.Stdout), &metadata); err != nil {
+		return nil, fmt.Errorf("reading chart metadata for release %s: %w", chart.Release, err)
+	}
+
+	for _, dependency := range metadata.Dependencies {
+		if len(dependency.Alias) > 0 {
+			subcharts = append(subcharts, dependency.Alias)
+		} else {
+			subcharts = append(subcharts, dependency.Name)
+		}
+	}
+
+	return subcharts, nil
+}
@@ -0,0 +1,54 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+func TestGetChartSubcharts(t *testing.T) {
+	useFakeHelm(t, `cat <<'EOF'
+apiVersion: v2
+name: web
+version: 1.0.0
+dependencies:
+  - name: postgresql
+    version: 12.x.x
+    repository: https://charts.bitnami.com/bitnami
+  - name: redis
+    alias: cache
+    version: 17.x.x
+    repository: https://charts.bitnami.com/bitnami
+EOF`)
+
+	subcharts, err := getChartSubcharts(config.ChartConfig{Name: "web", Repo: "example", Release: "web"})
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := []string{"postgresql", "cache"}
+	if !reflect.DeepEqual(subcharts, want) {
+		t.Errorf("want subcharts %v, got %v", want, subcharts)
+	}
+}
@@ -0,0 +1,337 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Traackr/binnacle/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// lockFileName is the name of the lock file, written next to the config file.  Config files in the same directory
+// share the lock file, and each locked release records the config file it was locked for.
+const lockFileName = "binnacle.lock"
+
+// lockFileHeader is written at the top of the lock file
+const lockFileHeader = "# This file is generated by `binnacle lock`. Do not edit it by hand.\n"
+
+var updateVersions bool
+
+// LockFile holds the resolved chart version of each release
+type LockFile struct {
+	Releases []LockedRelease `yaml:"releases"`
+}
+
+// LockedRelease is the chart version a release was resolved to
+type LockedRelease struct {
+	// Config is the name of the config file the release was locked for
+	Config    string `yaml:"config"`
+	Namespace string `yaml:"namespace"`
+	Release   string `yaml:"release"`
+	Chart     string `yaml:"chart"`
+	// Constraint is the version of the chart in the configuration when it was locked
+	Constraint string `yaml:"constraint,omitempty"`
+	Version    string `yaml:"version"`
+	Digest     string `yaml:"digest,omitempty"`
+}
+
+// lockCmd represents the lock command
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Resolves the chart version of each release and writes them to binnacle.lock.",
+	Long: `Resolves the chart version of each release against its repository index, and writes the version and
+chart digest to binnacle.lock, next to the config file.  The sync, diff and template commands use the
+locked versions unless --update is given.  Locked versions that still match the configuration are kept,
+unless --update is given.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		lockCmdPreRun()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return lockCmdRun(args...)
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		lockCmdPostRun()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(lockCmd)
+
+	lockCmd.Flags().BoolVar(&updateVersions, "update", false, "Resolve all versions again, instead of keeping the locked versions that still match the configuration.")
+}
+
+// addUpdateFlag adds the --update flag to commands that use the lock file
+func addUpdateFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&updateVersions, "update", false, "Resolve chart versions against the repository index instead of using "+lockFileName+".")
+}
+
+func lockCmdPreRun() {
+	log.Debug("Executing `lock` command.")
+}
+
+func lockCmdRun(args ...string) error {
+	// Load our configuration
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Sync repositories
+	if _, err := syncRepositories(c); err != nil {
+		return err
+	}
+
+	current := &LockFile{}
+	if !updateVersions {
+		current, err = readConfigLock(c.ConfigFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	var lock LockFile
+	var indexes = make(map[string]*RepoIndex)
+
+	for _, chart := range c.Charts {
+		if !lockableChart(chart) {
+			log.Debugf("Not locking release %s/%s: only charts from a repository are locked", chart.Namespace, chart.Release)
+			continue
+		}
+
+		if locked, found := current.Find(chart); found {
+			lock.Releases = append(lock.Releases, locked)
+			continue
+		}
+
+		index, err := cachedRepoIndex(indexes, chart.Repo)
+		if err != nil {
+			return err
+		}
+
+		entry, err := index.Resolve(chart.Name, chart.Version)
+		if err != nil {
+			return fmt.Errorf("locking release %s/%s: %w", chart.Namespace, chart.Release, err)
+		}
+
+		lock.Releases = append(lock.Releases, LockedRelease{
+			Namespace:  chart.Namespace,
+			Release:    chart.Release,
+			Chart:      chart.ChartURL(),
+			Constraint: chart.Version,
+			Version:    entry.Version,
+			Digest:     entry.Digest,
+		})
+
+		fmt.Printf("Locked %s/%s to %s %s\n", chart.Namespace, chart.Release, chart.ChartURL(), entry.Version)
+	}
+
+	return writeConfigLock(c.ConfigFile, lock)
+}
+
+func lockCmdPostRun() {
+	log.Debug("Execution of the `lock` command has completed.")
+}
+
+// lockFilePath returns the path of the lock file of the given config file
+func lockFilePath(configFile string) string {
+	return filepath.Join(filepath.Dir(configFile), lockFileName)
+}
+
+// lockableChart returns if the version of the chart can be locked, which requires a repository index
+func lockableChart(chart config.ChartConfig) bool {
+	return chart.State == config.StatePresent && len(chart.Repo) > 0 && !chart.IsOCI()
+}
+
+// readLockFile reads the lock file at the given path.  A missing lock file is empty.
+func readLockFile(path string) (*LockFile, error) {
+	var lock LockFile
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading lock file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("reading lock file %s: %w", path, err)
+	}
+
+	return &lock, nil
+}
+
+// readConfigLock reads the releases locked for the given config file from the lock file next to it
+func readConfigLock(configFile string) (*LockFile, error) {
+	var lock LockFile
+
+	all, err := readLockFile(lockFilePath(configFile))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, locked := range all.Releases {
+		if locked.Config == filepath.Base(configFile) {
+			lock.Releases = append(lock.Releases, locked)
+		}
+	}
+
+	return &lock, nil
+}
+
+// writeConfigLock writes the releases locked for the given config file to the lock file next to it, keeping the
+// releases locked for the other config files in the same directory
+func writeConfigLock(configFile string, lock LockFile) error {
+	var merged LockFile
+
+	path := lockFilePath(configFile)
+	name := filepath.Base(configFile)
+
+	all, err := readLockFile(path)
+	if err != nil {
+		return err
+	}
+
+	for _, locked := range all.Releases {
+		if locked.Config != name {
+			merged.Releases = append(merged.Releases, locked)
+		}
+	}
+
+	for _, locked := range lock.Releases {
+		locked.Config = name
+		merged.Releases = append(merged.Releases, locked)
+	}
+
+	// Group the releases by config file, keeping the order of the releases of each config file
+	sort.SliceStable(merged.Releases, func(i, j int) bool {
+		return merged.Releases[i].Config < merged.Releases[j].Config
+	})
+
+	return writeLockFile(path, merged)
+}
+
+// writeLockFile writes the lock file to the given path
+func writeLockFile(path string, lock LockFile) error {
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return fmt.Errorf("marshalling lock file: %w", err)
+	}
+
+	if err := os.WriteFile(path, append([]byte(lockFileHeader), data...), 0644); err != nil {
+		return fmt.Errorf("writing lock file: %w", err)
+	}
+
+	return nil
+}
+
+// Find returns the locked release of the chart, if its chart and version constraint did not change since it was
+// locked
+func (l *LockFile) Find(chart config.ChartConfig) (LockedRelease, bool) {
+	for _, locked := range l.Releases {
+		if locked.Namespace == chart.Namespace && locked.Release == chart.Release &&
+			locked.Chart == chart.ChartURL() && locked.Constraint == chart.Version {
+			return locked, true
+		}
+	}
+
+	return LockedRelease{}, false
+}
+
+// cachedRepoIndex loads the index of the repository once
+func cachedRepoIndex(indexes map[string]*RepoIndex, repo string) (*RepoIndex, error) {
+	if index, found := indexes[repo]; found {
+		return index, nil
+	}
+
+	index, err := loadRepoIndex(repo)
+	if err != nil {
+		return nil, err
+	}
+	indexes[repo] = index
+
+	return index, nil
+}
+
+// resolveChartVersions sets the version of each chart from a repository to an exact version.  Locked versions
+// are used unless update is set, after checking that the repository still serves the locked chart.  Other
+// version constraints are resolved against the repository index.
+func resolveChartVersions(c *config.BinnacleConfig, update bool) error {
+	lock := &LockFile{}
+	if !update {
+		var err error
+		lock, err = readConfigLock(c.ConfigFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	var indexes = make(map[string]*RepoIndex)
+
+	for idx := range c.Charts {
+		chart := &c.Charts[idx]
+
+		if !lockableChart(*chart) {
+			continue
+		}
+
+		locked, found := lock.Find(*chart)
+		// Exact versions and the latest version are left to helm
+		if !found && !chart.IsVersionRange() {
+			continue
+		}
+
+		index, err := cachedRepoIndex(indexes, chart.Repo)
+		if err != nil {
+			return err
+		}
+
+		if found {
+			entry, ok := index.Find(chart.Name, locked.Version)
+			if !ok {
+				return fmt.Errorf("using locked version of release %s/%s: version %s of chart %s is no longer in the repository index", chart.Namespace, chart.Release, locked.Version, chart.ChartURL())
+			}
+
+			if len(locked.Digest) > 0 && entry.Digest != locked.Digest {
+				return fmt.Errorf("using locked version of release %s/%s: digest of chart %s %s changed from %s to %s", chart.Namespace, chart.Release, chart.ChartURL(), locked.Version, locked.Digest, entry.Digest)
+			}
+
+			chart.Version = locked.Version
+			continue
+		}
+
+		entry, err := index.Resolve(chart.Name, chart.Version)
+		if err != nil {
+			return fmt.Errorf("resolving release %s/%s: %w", chart.Namespace, chart.Release, err)
+		}
+
+		log.Debugf("Resolved release %s/%s to %s %s", chart.Namespace, chart.Release, chart.ChartURL(), entry.Version)
+		chart.Version = entry.Version
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), lockFileName)
+
+	// A missing lock file is empty
+	lock, err := readLockFile(path)
+	if err != nil {
+		t.Fatalf("want no error for a missing lock file, got %v", err)
+	}
+	if len(lock.Releases) != 0 {
+		t.Errorf("want no locked releases, got %v", lock.Releases)
+	}
+
+	want := LockFile{Releases: []LockedRelease{
+		{Namespace: "apps", Release: "apps-concourse", Chart: "stable/concourse", Constraint: "~1.3", Version: "1.3.4", Digest: "sha256:1d34"},
+		{Namespace: "apps", Release: "apps-web", Chart: "stable/web", Version: "2.0.0"},
+	}}
+
+	if err := writeLockFile(path, want); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), lockFileHeader) {
+		t.Errorf("want the lock file to start with %q, got %q", lockFileHeader, data)
+	}
+
+	got, err := readLockFile(path)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("want lock file %v, got %v", want, *got)
+	}
+}
+
+func TestConfigLock_SharedLockFile(t *testing.T) {
+	dir := t.TempDir()
+	prod := filepath.Join(dir, "prod.yml")
+	staging := filepath.Join(dir, "staging.yml")
+
+	prodLock := LockFile{Releases: []LockedRelease{
+		{Namespace: "apps", Release: "apps-web", Chart: "stable/web", Version: "2.0.0"},
+	}}
+	stagingLock := LockFile{Releases: []LockedRelease{
+		{Namespace: "apps", Release: "apps-web", Chart: "stable/web", Version: "2.1.0"},
+	}}
+
+	if err := writeConfigLock(staging, stagingLock); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if err := writeConfigLock(prod, prodLock); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	// Locking prod again keeps the releases locked for staging
+	prodLock.Releases[0].Version = "2.0.1"
+	if err := writeConfigLock(prod, prodLock); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	tests := map[string]string{prod: "2.0.1", staging: "2.1.0"}
+	for configFile, version := range tests {
+		lock, err := readConfigLock(configFile)
+		if err != nil {
+			t.Fatalf("want no error, got %v", err)
+		}
+
+		if len(lock.Releases) != 1 || lock.Releases[0].Version != version {
+			t.Errorf("want %s locked to %s, got %v", filepath.Base(configFile), version, lock.Releases)
+		}
+	}
+
+	all, err := readLockFile(filepath.Join(dir, lockFileName))
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	var configs []string
+	for _, locked := range all.Releases {
+		configs = append(configs, locked.Config)
+	}
+	if want := []string{"prod.yml", "staging.yml"}; !reflect.DeepEqual(configs, want) {
+		t.Errorf("want releases locked for %v, got %v", want, configs)
+	}
+}
+
+func TestLockFileFind(t *testing.T) {
+	lock := LockFile{Releases: []LockedRelease{
+		{Namespace: "apps", Release: "apps-concourse", Chart: "stable/concourse", Constraint: "~1.3", Version: "1.3.4"},
+	}}
+
+	tests := []struct {
+		chart config.ChartConfig
+		found bool
+	}{
+		{config.ChartConfig{Namespace: "apps", Release: "apps-concourse", Repo: "stable", Name: "concourse", Version: "~1.3"}, true},
+		{config.ChartConfig{Namespace: "apps", Release: "apps-concourse", Repo: "stable", Name: "concourse", Version: "~1.4"}, false},
+		{config.ChartConfig{Namespace: "apps", Release: "apps-concourse", Repo: "mirror", Name: "concourse", Version: "~1.3"}, false},
+		{config.ChartConfig{Namespace: "ci", Release: "apps-concourse", Repo: "stable", Name: "concourse", Version: "~1.3"}, false},
+	}
+
+	for _, test := range tests {
+		locked, found := lock.Find(test.chart)
+		if found != test.found {
+			t.Errorf("want found %v for %s %s, got %v", test.found, test.chart.ChartURL(), test.chart.Version, found)
+		}
+		if found && locked.Version != "1.3.4" {
+			t.Errorf("want locked version 1.3.4, got %s", locked.Version)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+// Change types reported when comparing two sets of resources
+const (
+	ChangeAdded   = "added"
+	ChangeChanged = "changed"
+	ChangeRemoved = "removed"
+)
+
+// Resource is a single Kubernetes object parsed from a rendered manifest
+type Resource struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	Object     map[string]any
+	// Raw is the document of the resource as it appeared in the manifest
+	Raw string
+	// Source is the chart template the resource was rendered from, if known
+	Source string
+}
+
+// ID returns the identifier used to match resources between manifests
+func (r Resource) ID() string {
+	return fmt.Sprintf("%s/%s/%s/%s", r.APIVersion, r.Kind, r.Namespace, r.Name)
+}
+
+// String returns a human readable description of the resource
+func (r Resource) String() string {
+	if len(r.Namespace) > 0 {
+		return fmt.Sprintf("%s, %s, %s (%s)", r.Namespace, r.Name, r.Kind, r.APIVersion)
+	}
+	return fmt.Sprintf("%s, %s (%s)", r.Name, r.Kind, r.APIVersion)
+}
+
+// YAML returns the normalized YAML representation of the resource
+func (r Resource) YAML() (string, error) {
+	if r.Object == nil {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(r.Object); err != nil {
+		return "", fmt.Errorf("marshalling resource %s: %w", r, err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("marshalling resource %s: %w", r, err)
+	}
+
+	return buf.String(), nil
+}
+
+// ResourceDiff describes the difference of a single resource between two manifests
+type ResourceDiff struct {
+	Resource Resource
+	Change   string
+	Diff     string
+}
+
+// ParseManifest splits a multi-document manifest into its resources
+func ParseManifest(manifest string) ([]Resource, error) {
+	var resources []Resource
+
+	for _, doc := range splitManifest(manifest) {
+		var obj map[string]any
+
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			return nil, fmt.Errorf("parsing manifest: %w", err)
+		}
+
+		// Skip empty documents
+		if len(obj) == 0 {
+			continue
+		}
+
+		var res Resource
+		res.Object = obj
+		res.Raw = doc
+		res.Source = sourceComment(doc)
+		res.APIVersion, _ = obj["apiVersion"].(string)
+		res.Kind, _ = obj["kind"].(string)
+		if metadata, ok := obj["metadata"].(map[string]any); ok {
+			res.Name, _ = metadata["name"].(string)
+			res.Namespace, _ = metadata["namespace"].(string)
+		}
+
+		resources = append(resources, res)
+	}
+
+	return resources, nil
+}
+
+// splitManifest splits a manifest on its document separators
+func splitManifest(manifest string) []string {
+	var docs []string
+	var doc []string
+
+	for _, line := range strings.Split(manifest, "\n") {
+		if line == "---" || strings.HasPrefix(line, "--- ") {
+			docs = append(docs, strings.Join(doc, "\n"))
+			doc = nil
+			continue
+		}
+		doc = append(doc, line)
+	}
+	docs = append(docs, strings.Join(doc, "\n"))
+
+	var res []string
+	for _, d := range docs {
+		if len(strings.TrimSpace(d)) > 0 {
+			res = append(res, strings.TrimSpace(d)+"\n")
+		}
+	}
+
+	return res
+}
+
+// sourceComment returns the template named by the `# Source:` comment helm adds to the top of each document
+func sourceComment(doc string) string {
+	for _, line := range strings.Split(doc, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		if source := strings.TrimPrefix(line, "# Source:"); source != line {
+			return strings.TrimSpace(source)
+		}
+	}
+
+	return ""
+}
+
+// formatManifest joins the given resources back into a multi-document manifest
+func formatManifest(resources []Resource) string {
+	var docs []string
+	for _, r := range resources {
+		docs = append(docs, "---\n"+r.Raw)
+	}
+	return strings.TrimSuffix(strings.Join(docs, ""), "\n")
+}
+
+// DiffResources compares the current and desired resources, matching them by apiVersion, kind, namespace
+// and name, and returns a diff for each resource that was added, changed or removed
+func DiffResources(current []Resource, desired []Resource) ([]ResourceDiff, error) {
+	var diffs []ResourceDiff
+
+	before := make(map[string]Resource)
+	after := make(map[string]Resource)
+	var ids []string
+
+	for _, r := range current {
+		if _, ok := before[r.ID()]; !ok {
+			ids = append(ids, r.ID())
+		}
+		before[r.ID()] = r
+	}
+	for _, r := range desired {
+		if _, ok := before[r.ID()]; !ok {
+			if _, ok := after[r.ID()]; !ok {
+				ids = append(ids, r.ID())
+			}
+		}
+		after[r.ID()] = r
+	}
+
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		var diff ResourceDiff
+
+		oldRes, inBefore := before[id]
+		newRes, inAfter := after[id]
+
+		switch {
+		case inBefore && inAfter:
+			diff.Change = ChangeChanged
+			diff.Resource = newRes
+		case inAfter:
+			diff.Change = ChangeAdded
+			diff.Resource = newRes
+		default:
+			diff.Change = ChangeRemoved
+			diff.Resource = oldRes
+		}
+
+		text, err := unifiedDiff(oldRes, newRes)
+		if err != nil {
+			return nil, err
+		}
+
+		// Resources that exist on both sides without differences are not reported
+		if len(text) == 0 {
+			continue
+		}
+		diff.Diff = text
+
+		diffs = append(diffs, diff)
+	}
+
+	return diffs, nil
+}
+
+func unifiedDiff(before Resource, after Resource) (string, error) {
+	a, err := before.YAML()
+	if err != nil {
+		return "", err
+	}
+
+	b, err := after.YAML()
+	if err != nil {
+		return "", err
+	}
+
+	if a == b {
+		return "", nil
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(a),
+		B:        splitLines(b),
+		FromFile: "current",
+		ToFile:   "desired",
+		Context:  3,
+	})
+}
+
+func splitLines(s string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	return difflib.SplitLines(strings.TrimSuffix(s, "\n"))
+}
@@ -0,0 +1,157 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+const currentManifest = `---
+# Source: web/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+  namespace: apps
+data:
+  replicas: "1"
+---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: apps
+spec:
+  type: ClusterIP
+`
+
+const desiredManifest = `---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  namespace: apps
+  name: web
+spec:
+  type: ClusterIP
+---
+# Source: web/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+  namespace: apps
+data:
+  replicas: "3"
+---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: apps
+`
+
+func TestParseManifest(t *testing.T) {
+	resources, err := ParseManifest(currentManifest + "---\n\n---\n# Source: web/templates/empty.yaml\n")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if len(resources) != 2 {
+		t.Fatalf("want 2 resources, got %d", len(resources))
+	}
+
+	got := resources[1].ID()
+	want := "v1/Service/apps/web"
+	if got != want {
+		t.Errorf("want resource id %s, got %s", want, got)
+	}
+
+	got = resources[1].Source
+	want = "web/templates/service.yaml"
+	if got != want {
+		t.Errorf("want resource source %s, got %s", want, got)
+	}
+}
+
+func TestFormatManifest(t *testing.T) {
+	resources, _ := ParseManifest(currentManifest)
+
+	got := formatManifest(resources)
+	want := strings.TrimSpace(currentManifest)
+	if got != want {
+		t.Errorf("want formatted manifest:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestParseManifest_Invalid(t *testing.T) {
+	_, err := ParseManifest("kind: [")
+	if err == nil {
+		t.Errorf("want an error for an invalid manifest, but was nil")
+	}
+}
+
+func TestDiffResources(t *testing.T) {
+	current, _ := ParseManifest(currentManifest)
+	desired, _ := ParseManifest(desiredManifest)
+
+	diffs, err := DiffResources(current, desired)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if len(diffs) != 2 {
+		t.Fatalf("want 2 diffs, got %d", len(diffs))
+	}
+
+	if diffs[0].Resource.Kind != "Deployment" || diffs[0].Change != ChangeAdded {
+		t.Errorf("want Deployment to be %s, got %s %s", ChangeAdded, diffs[0].Resource.Kind, diffs[0].Change)
+	}
+
+	if diffs[1].Resource.Kind != "ConfigMap" || diffs[1].Change != ChangeChanged {
+		t.Errorf("want ConfigMap to be %s, got %s %s", ChangeChanged, diffs[1].Resource.Kind, diffs[1].Change)
+	}
+
+	if !strings.Contains(diffs[1].Diff, `-  replicas: "1"`) || !strings.Contains(diffs[1].Diff, `+  replicas: "3"`) {
+		t.Errorf("want ConfigMap diff to contain the replicas change, got:\n%s", diffs[1].Diff)
+	}
+}
+
+func TestDiffResources_Removed(t *testing.T) {
+	current, _ := ParseManifest(currentManifest)
+
+	diffs, _ := DiffResources(current, nil)
+	if len(diffs) != 2 {
+		t.Fatalf("want 2 diffs, got %d", len(diffs))
+	}
+
+	for _, diff := range diffs {
+		if diff.Change != ChangeRemoved {
+			t.Errorf("want %s to be %s, got %s", diff.Resource, ChangeRemoved, diff.Change)
+		}
+		if strings.Contains(diff.Diff, "\n+ ") {
+			t.Errorf("want removed resource diff to only contain removals, got:\n%s", diff.Diff)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/Traackr/binnacle/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Rewrites older layouts of the config file into the current one.",
+	Long: `Rewrites older layouts of the config file into the current one, and prints what changed.  Charts with
+a url as their name are given by url, and the apiVersion is added.  The rest of the config file, including its
+comments, is left untouched.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		migrateCmdPreRun()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return migrateCmdRun(args...)
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		migrateCmdPostRun()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(migrateCmd)
+}
+
+func migrateCmdPreRun() {
+	log.Debug("Executing `migrate` command.")
+}
+
+func migrateCmdRun(args ...string) error {
+	configFile := viper.ConfigFileUsed()
+
+	doc, err := config.ReadDocument(configFile)
+	if err != nil {
+		return err
+	}
+
+	changes, err := config.Migrate(doc)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Printf("%s is up to date.\n", configFile)
+		return nil
+	}
+
+	if err := writeDocument(configFile, doc); err != nil {
+		return err
+	}
+
+	fmt.Printf("Migrated %s to %s:\n", configFile, config.APIVersion)
+	for _, change := range changes {
+		fmt.Printf("  - %s\n", change)
+	}
+
+	return nil
+}
+
+func migrateCmdPostRun() {
+	log.Debug("Execution of the `migrate` command has completed.")
+}
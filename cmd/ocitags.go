@@ -0,0 +1,208 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+// registryCredentials are the credentials used for the requests to an OCI registry
+type registryCredentials struct {
+	Username string
+	Password string
+}
+
+// listOCITags returns the tags of the given oci:// chart, using the registry API.  Insecure registries are
+// reached over http.  Helm replaces the + of semver build metadata with _ in tags, which is reverted.
+func listOCITags(ref string, creds registryCredentials, insecure bool) ([]string, error) {
+	host, repository, found := strings.Cut(strings.TrimPrefix(ref, config.OCIScheme), "/")
+	if !found {
+		return nil, fmt.Errorf("listing tags of %s: missing repository", ref)
+	}
+
+	var tags []string
+	var token string
+	var authenticated bool
+
+	scheme := "https"
+	if insecure {
+		scheme = "http"
+	}
+
+	next := fmt.Sprintf("%s://%s/v2/%s/tags/list", scheme, host, repository)
+	for len(next) > 0 {
+		resp, err := registryGet(next, token, creds)
+		if err != nil {
+			return nil, fmt.Errorf("listing tags of %s: %w", ref, err)
+		}
+
+		// Authenticate once when the registry asks for it, and retry
+		if resp.StatusCode == http.StatusUnauthorized && !authenticated {
+			authenticated = true
+			token, err = registryToken(resp.Header.Get("WWW-Authenticate"), creds)
+			resp.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("listing tags of %s: %w", ref, err)
+			}
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("listing tags of %s: unexpected status %s", ref, resp.Status)
+		}
+
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("listing tags of %s: %w", ref, err)
+		}
+
+		for _, tag := range page.Tags {
+			tags = append(tags, strings.ReplaceAll(tag, "_", "+"))
+		}
+
+		next, err = nextPage(next, resp.Header.Get("Link"))
+		if err != nil {
+			return nil, fmt.Errorf("listing tags of %s: %w", ref, err)
+		}
+	}
+
+	return tags, nil
+}
+
+// registryGet sends a GET request to the registry, with the bearer token if there is one, or else the basic
+// credentials if there are any
+func registryGet(u string, token string, creds registryCredentials) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(token) > 0:
+		req.Header.Set("Authorization", "Bearer "+token)
+	case len(creds.Username) > 0:
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+
+	return httpClient.Do(req)
+}
+
+// registryToken requests a bearer token for the given WWW-Authenticate challenge.  Basic challenges need no
+// token, as the basic credentials are sent with every request.
+func registryToken(challenge string, creds registryCredentials) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		if len(creds.Username) == 0 {
+			return "", fmt.Errorf("registry requires credentials, add the registry to the registries section")
+		}
+		return "", nil
+	}
+
+	values := parseChallenge(params)
+
+	realm, err := url.Parse(values["realm"])
+	if err != nil || len(values["realm"]) == 0 {
+		return "", fmt.Errorf("reading registry token realm from %q", challenge)
+	}
+
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if len(values[key]) > 0 {
+			query.Set(key, values[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	resp, err := registryGet(realm.String(), "", creds)
+	if err != nil {
+		return "", fmt.Errorf("requesting registry token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("requesting registry token: unexpected status %s", resp.Status)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		Token       string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("reading registry token: %w", err)
+	}
+
+	if len(body.Token) > 0 {
+		return body.Token, nil
+	}
+
+	return body.AccessToken, nil
+}
+
+// parseChallenge parses the comma separated key="value" parameters of a WWW-Authenticate challenge
+func parseChallenge(params string) map[string]string {
+	values := make(map[string]string)
+
+	for len(params) > 0 {
+		var key, value string
+
+		key, params, _ = strings.Cut(strings.TrimLeft(params, ", "), "=")
+		if strings.HasPrefix(params, `"`) {
+			value, params, _ = strings.Cut(params[1:], `"`)
+		} else {
+			value, params, _ = strings.Cut(params, ",")
+		}
+
+		values[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+
+	return values
+}
+
+// nextPage returns the url of the next page given in a Link header, resolved against the current url
+func nextPage(current string, link string) (string, error) {
+	target, rel, found := strings.Cut(link, ";")
+	if !found || !strings.Contains(rel, `rel="next"`) {
+		return "", nil
+	}
+
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", err
+	}
+
+	next, err := base.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+	if err != nil {
+		return "", err
+	}
+
+	return next.String(), nil
+}
@@ -0,0 +1,292 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/Traackr/binnacle/config"
+	"github.com/spf13/cobra"
+)
+
+// Supported outdated and config view output formats
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+	outputYAML  = "yaml"
+)
+
+var outdatedOffline bool
+var outdatedOutput string
+
+// ChartVersion is a version of a chart, with the version of the application it deploys
+type ChartVersion struct {
+	Version    string `json:"version"`
+	AppVersion string `json:"appVersion,omitempty"`
+}
+
+func (v ChartVersion) String() string {
+	if len(v.AppVersion) == 0 {
+		return v.Version
+	}
+
+	return fmt.Sprintf("%s (%s)", v.Version, v.AppVersion)
+}
+
+// OutdatedRelease holds the current version of the chart of a release, and the newest available versions
+type OutdatedRelease struct {
+	Namespace string       `json:"namespace"`
+	Release   string       `json:"release"`
+	Chart     string       `json:"chart"`
+	Current   ChartVersion `json:"current"`
+	Patch     ChartVersion `json:"patch"`
+	Minor     ChartVersion `json:"minor"`
+	Major     ChartVersion `json:"major"`
+}
+
+// outdatedCmd represents the outdated command
+var outdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "Compares the chart version of each release with the versions available in its repository.",
+	Long: `Compares the chart version of each release with the versions available in its repository index, or in
+its OCI registry, and shows the newest patch, minor and major versions.  With --offline, the repository
+indexes cached by helm are used as they are, and OCI charts are skipped.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		outdatedCmdPreRun()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return outdatedCmdRun(args...)
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		outdatedCmdPostRun()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(outdatedCmd)
+
+	outdatedCmd.Flags().BoolVar(&outdatedOffline, "offline", false, "Use the cached repository indexes without updating them, and skip OCI charts.")
+	outdatedCmd.Flags().StringVarP(&outdatedOutput, "output", "o", outputTable, "Output format, one of: table, json.")
+}
+
+func outdatedCmdPreRun() {
+	log.Debug("Executing `outdated` command.")
+}
+
+func outdatedCmdRun(args ...string) error {
+	if outdatedOutput != outputTable && outdatedOutput != outputJSON {
+		return fmt.Errorf("checking outdated flags: unsupported output format %q, expected table or json", outdatedOutput)
+	}
+
+	// Load our configuration
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Only refresh the repository indexes, so that outdated never changes the helm repositories
+	if !outdatedOffline {
+		if err := refreshRepositories(c); err != nil {
+			return err
+		}
+	}
+
+	lock, err := readConfigLock(c.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	var releases []OutdatedRelease
+	var indexes = make(map[string]*RepoIndex)
+
+	for _, chart := range c.Charts {
+		var versions []IndexEntry
+
+		if chart.State != config.StatePresent {
+			continue
+		}
+
+		switch {
+		case chart.IsOCI() && outdatedOffline:
+			log.Infof("Skipping release %s/%s: OCI charts can not be checked offline", chart.Namespace, chart.Release)
+			continue
+		case chart.IsOCI():
+			versions, err = ociChartVersions(c, chart)
+			if err != nil {
+				return err
+			}
+		case len(chart.Repo) > 0:
+			index, err := cachedRepoIndex(indexes, chart.Repo)
+			if err != nil {
+				return err
+			}
+			versions = index.Versions(chart.Name)
+		default:
+			log.Debugf("Skipping release %s/%s: only charts from a repository or registry have versions to compare", chart.Namespace, chart.Release)
+			continue
+		}
+
+		release, err := outdatedRelease(chart, lock, versions)
+		if err != nil {
+			return err
+		}
+		releases = append(releases, release)
+	}
+
+	return writeOutdatedReleases(os.Stdout, outdatedOutput, releases)
+}
+
+func outdatedCmdPostRun() {
+	log.Debug("Execution of the `outdated` command has completed.")
+}
+
+// ociChartVersions lists the versions of an OCI chart from the tags in its registry
+func ociChartVersions(c *config.BinnacleConfig, chart config.ChartConfig) ([]IndexEntry, error) {
+	var creds registryCredentials
+	var entries []IndexEntry
+	var insecure bool
+
+	host, _, _ := strings.Cut(strings.TrimPrefix(chart.ChartURL(), config.OCIScheme), "/")
+	for _, registry := range c.Registries {
+		if registry.LoginHost() != host {
+			continue
+		}
+
+		username, password, err := registry.Credentials(filepath.Dir(c.ConfigFile))
+		if err != nil {
+			return nil, err
+		}
+		creds = registryCredentials{Username: username, Password: password}
+		insecure = registry.Insecure
+	}
+
+	tags, err := listOCITags(chart.ChartURL(), creds, insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range tags {
+		entries = append(entries, IndexEntry{Name: chart.Name, Version: tag})
+	}
+
+	return sortVersions(entries), nil
+}
+
+// outdatedRelease compares the current version of the chart with the sorted versions that are available.  The
+// current version is the locked version, the configured version, or the newest version matching the
+// configured constraint.
+func outdatedRelease(chart config.ChartConfig, lock *LockFile, versions []IndexEntry) (OutdatedRelease, error) {
+	release := OutdatedRelease{
+		Namespace: chart.Namespace,
+		Release:   chart.Release,
+		Chart:     chart.ChartURL(),
+	}
+
+	current := chart.Version
+	if locked, found := lock.Find(chart); found {
+		current = locked.Version
+	} else if len(current) == 0 || chart.IsVersionRange() {
+		entry, err := resolveVersion(versions, chart.Version)
+		if err != nil {
+			return release, fmt.Errorf("resolving version of release %s/%s: %w", chart.Namespace, chart.Release, err)
+		}
+		current = entry.Version
+	}
+
+	currentVersion, err := semver.NewVersion(current)
+	if err != nil {
+		return release, fmt.Errorf("reading version of release %s/%s: %w", chart.Namespace, chart.Release, err)
+	}
+
+	release.Current = ChartVersion{Version: current}
+	release.Patch = release.Current
+	release.Minor = release.Current
+	release.Major = release.Current
+
+	for _, entry := range versions {
+		if entry.Version == current {
+			release.Current.AppVersion = entry.AppVersion
+		}
+	}
+
+	// The versions are sorted newest first, so the first match of each kind is the newest
+	var patchFound, minorFound, majorFound bool
+	for _, entry := range versions {
+		v, _ := semver.NewVersion(entry.Version)
+		if len(v.Prerelease()) > 0 || v.LessThan(currentVersion) {
+			continue
+		}
+
+		version := ChartVersion{Version: entry.Version, AppVersion: entry.AppVersion}
+
+		if !majorFound {
+			release.Major = version
+			majorFound = true
+		}
+
+		if !minorFound && v.Major() == currentVersion.Major() {
+			release.Minor = version
+			minorFound = true
+		}
+
+		if !patchFound && v.Major() == currentVersion.Major() && v.Minor() == currentVersion.Minor() {
+			release.Patch = version
+			patchFound = true
+		}
+	}
+
+	return release, nil
+}
+
+// writeOutdatedReleases writes the releases in the given output format
+func writeOutdatedReleases(w io.Writer, format string, releases []OutdatedRelease) error {
+	if format == outputJSON {
+		if releases == nil {
+			releases = []OutdatedRelease{}
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(releases); err != nil {
+			return fmt.Errorf("writing outdated releases: %w", err)
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RELEASE\tCHART\tCURRENT\tPATCH\tMINOR\tMAJOR")
+	for _, r := range releases {
+		fmt.Fprintf(tw, "%s/%s\t%s\t%s\t%s\t%s\t%s\n", r.Namespace, r.Release, r.Chart, r.Current, r.Patch, r.Minor, r.Major)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("writing outdated releases: %w", err)
+	}
+
+	return nil
+}
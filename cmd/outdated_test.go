@@ -0,0 +1,112 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+func TestOutdatedRelease(t *testing.T) {
+	index, err := readRepoIndex("../testdata/repo-index.yaml")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	versions := index.Versions("concourse")
+
+	lock := &LockFile{Releases: []LockedRelease{
+		{Namespace: "apps", Release: "locked", Chart: "stable/concourse", Constraint: "~1.3", Version: "1.3.1"},
+	}}
+
+	tests := []struct {
+		release string
+		version string
+		want    [4]string
+	}{
+		{"exact", "1.3.1", [4]string{"1.3.1 (3.10.0)", "1.3.4 (3.10.0)", "1.10.0 (3.14.1)", "2.1.0 (7.1.0)"}},
+		{"locked", "~1.3", [4]string{"1.3.1 (3.10.0)", "1.3.4 (3.10.0)", "1.10.0 (3.14.1)", "2.1.0 (7.1.0)"}},
+		{"range", "~1.3", [4]string{"1.3.4 (3.10.0)", "1.3.4 (3.10.0)", "1.10.0 (3.14.1)", "2.1.0 (7.1.0)"}},
+		{"latest", "", [4]string{"2.1.0 (7.1.0)", "2.1.0 (7.1.0)", "2.1.0 (7.1.0)", "2.1.0 (7.1.0)"}},
+	}
+
+	for _, test := range tests {
+		chart := config.ChartConfig{Namespace: "apps", Release: test.release, Repo: "stable", Name: "concourse", Version: test.version}
+
+		release, err := outdatedRelease(chart, lock, versions)
+		if err != nil {
+			t.Fatalf("want no error for %s, got %v", test.release, err)
+		}
+
+		got := [4]string{release.Current.String(), release.Patch.String(), release.Minor.String(), release.Major.String()}
+		if got != test.want {
+			t.Errorf("want versions %q for %s, got %q", test.want, test.release, got)
+		}
+	}
+}
+
+func TestWriteOutdatedReleases(t *testing.T) {
+	releases := []OutdatedRelease{{
+		Namespace: "apps",
+		Release:   "apps-concourse",
+		Chart:     "stable/concourse",
+		Current:   ChartVersion{Version: "1.3.1", AppVersion: "3.10.0"},
+		Patch:     ChartVersion{Version: "1.3.4", AppVersion: "3.10.0"},
+		Minor:     ChartVersion{Version: "1.10.0", AppVersion: "3.14.1"},
+		Major:     ChartVersion{Version: "2.1.0", AppVersion: "7.1.0"},
+	}}
+
+	var table bytes.Buffer
+	if err := writeOutdatedReleases(&table, outputTable, releases); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "RELEASE") {
+		t.Fatalf("want a header and one row, got %q", table.String())
+	}
+	for _, want := range []string{"apps/apps-concourse", "stable/concourse", "1.3.1 (3.10.0)", "2.1.0 (7.1.0)"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("want row to contain %q, got %q", want, lines[1])
+		}
+	}
+
+	var out bytes.Buffer
+	if err := writeOutdatedReleases(&out, outputJSON, releases); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	var got []OutdatedRelease
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("want valid JSON, got %v", err)
+	}
+	if len(got) != 1 || got[0] != releases[0] {
+		t.Errorf("want %v, got %v", releases, got)
+	}
+
+	out.Reset()
+	if err := writeOutdatedReleases(&out, outputJSON, nil); err != nil || strings.TrimSpace(out.String()) != "[]" {
+		t.Errorf("want an empty JSON list, got %q (%v)", out.String(), err)
+	}
+}
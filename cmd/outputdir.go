@@ -0,0 +1,140 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+// defaultNamespace is the directory used for releases without a namespace
+const defaultNamespace = "default"
+
+// outputMarker is the file that marks a release directory as written by binnacle, so that --clean only removes
+// files from directories it owns
+const outputMarker = ".binnacle"
+
+const outputMarkerContent = "This directory is written by binnacle template --output-dir, stale files are removed with --clean.\n"
+
+// resourceFilePath returns the path of the file a resource of the given release is written to
+func resourceFilePath(dir string, chart config.ChartConfig, r Resource) string {
+	namespace := chart.Namespace
+	if len(namespace) == 0 {
+		namespace = defaultNamespace
+	}
+
+	filename := fmt.Sprintf("%s-%s.yaml", strings.ToLower(r.Kind), r.Name)
+
+	return filepath.Join(dir, namespace, chart.Release, filename)
+}
+
+// writeResourceFiles writes each resource of the release to its own file, recording the written files
+func writeResourceFiles(dir string, chart config.ChartConfig, resources []Resource, written map[string]bool) error {
+	for _, r := range resources {
+		path := resourceFilePath(dir, chart, r)
+
+		if written[path] {
+			return fmt.Errorf("writing resource %s: %s was already written by another resource", r, path)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("creating output directory: %w", err)
+		}
+
+		marker := filepath.Join(filepath.Dir(path), outputMarker)
+		if !written[marker] {
+			if err := os.WriteFile(marker, []byte(outputMarkerContent), 0644); err != nil {
+				return fmt.Errorf("creating output directory: %w", err)
+			}
+			written[marker] = true
+		}
+
+		if err := os.WriteFile(path, []byte(r.Raw), 0644); err != nil {
+			return fmt.Errorf("writing resource %s: %w", r, err)
+		}
+
+		log.Debugf("Wrote %s", path)
+		written[path] = true
+	}
+
+	return nil
+}
+
+// removeStaleFiles removes the yaml files that were not written from the release directories that binnacle
+// owns, which are marked by the output marker.  Release directories that are left without any files are
+// removed, along with their namespace directory when it is left empty.  Other files and directories are never
+// touched.
+func removeStaleFiles(dir string, written map[string]bool) error {
+	markers, err := filepath.Glob(filepath.Join(dir, "*", "*", outputMarker))
+	if err != nil {
+		return fmt.Errorf("removing stale files: %w", err)
+	}
+
+	for _, marker := range markers {
+		releaseDir := filepath.Dir(marker)
+
+		entries, err := os.ReadDir(releaseDir)
+		if err != nil {
+			return fmt.Errorf("removing stale files: %w", err)
+		}
+
+		var remaining int
+		for _, entry := range entries {
+			path := filepath.Join(releaseDir, entry.Name())
+
+			switch {
+			case entry.Name() == outputMarker:
+			case entry.IsDir() || filepath.Ext(path) != ".yaml" || written[path]:
+				remaining++
+			default:
+				log.Infof("Removing stale file %s", path)
+				if err := os.Remove(path); err != nil {
+					return fmt.Errorf("removing stale files: %w", err)
+				}
+			}
+		}
+
+		if remaining > 0 {
+			continue
+		}
+
+		if err := os.Remove(marker); err != nil {
+			return fmt.Errorf("removing stale directories: %w", err)
+		}
+		if err := os.Remove(releaseDir); err != nil {
+			return fmt.Errorf("removing stale directories: %w", err)
+		}
+
+		// The namespace directory is only removed when the release was the last thing in it
+		namespaceDir := filepath.Dir(releaseDir)
+		if entries, err := os.ReadDir(namespaceDir); err == nil && len(entries) == 0 {
+			if err := os.Remove(namespaceDir); err != nil {
+				return fmt.Errorf("removing stale directories: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+func TestWriteResourceFiles(t *testing.T) {
+	dir := t.TempDir()
+	chart := config.ChartConfig{Namespace: "apps", Release: "web"}
+	resources, _ := ParseManifest(currentManifest)
+
+	// Files from a previous run
+	stale := filepath.Join(dir, "apps", "old", "deployment-old.yaml")
+	os.MkdirAll(filepath.Dir(stale), 0755)
+	os.WriteFile(stale, []byte("kind: Deployment"), 0644)
+	os.WriteFile(filepath.Join(dir, "apps", "old", outputMarker), []byte(outputMarkerContent), 0644)
+	readme := filepath.Join(dir, "README.md")
+	os.WriteFile(readme, []byte("# Rendered manifests"), 0644)
+
+	written := make(map[string]bool)
+	if err := writeResourceFiles(dir, chart, resources, written); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "apps", "web", "configmap-web.yaml"))
+	if err != nil {
+		t.Fatalf("want configmap file to be written, got %v", err)
+	}
+	if string(data) != resources[0].Raw {
+		t.Errorf("want configmap file to contain:\n%s\ngot:\n%s", resources[0].Raw, data)
+	}
+
+	if err := removeStaleFiles(dir, written); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "apps", "old")); !os.IsNotExist(err) {
+		t.Errorf("want stale release directory to be removed, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "apps", "web", "service-web.yaml")); err != nil {
+		t.Errorf("want written file to be kept, got %v", err)
+	}
+
+	if _, err := os.Stat(readme); err != nil {
+		t.Errorf("want non yaml files to be kept, got %v", err)
+	}
+}
+
+func TestWriteResourceFiles_Duplicate(t *testing.T) {
+	chart := config.ChartConfig{Namespace: "apps", Release: "web"}
+	resources, _ := ParseManifest(currentManifest + "---\n" + currentManifest)
+
+	err := writeResourceFiles(t.TempDir(), chart, resources, make(map[string]bool))
+	if err == nil {
+		t.Errorf("want an error for duplicate resources, but was nil")
+	}
+}
+
+func TestRemoveStaleFiles_KeepsUnownedFiles(t *testing.T) {
+	dir := t.TempDir()
+	chart := config.ChartConfig{Namespace: "apps", Release: "web"}
+	resources, _ := ParseManifest(currentManifest)
+
+	// Files that share the output directory, but were not written by binnacle
+	unrelated := []string{
+		filepath.Join(dir, "binnacle.yaml"),
+		filepath.Join(dir, "apps", "patch.yaml"),
+		filepath.Join(dir, "charts", "web", "values.yaml"),
+	}
+	for _, path := range unrelated {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("key: value"), 0644)
+	}
+
+	written := make(map[string]bool)
+	if err := writeResourceFiles(dir, chart, resources, written); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if err := removeStaleFiles(dir, written); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	for _, path := range unrelated {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("want %s to be kept, got %v", path, err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "apps", "web", "service-web.yaml")); err != nil {
+		t.Errorf("want written file to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/Traackr/binnacle/config"
+	"github.com/spf13/cobra"
+)
+
+var setValues []string
+var setFileValues []string
+var setStringValues []string
+
+// addValueOverrideFlags adds the flags used to override chart values from the command line
+func addValueOverrideFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&setValues, "set", nil, "Set a value for a single release, as namespace/release:key.path=value. Can be repeated.")
+	cmd.Flags().StringArrayVar(&setFileValues, "set-file", nil, "Set a value for a single release from the contents of a file, as namespace/release:key.path=filepath. Can be repeated.")
+	cmd.Flags().StringArrayVar(&setStringValues, "set-string", nil, "Set a string value for a single release, as namespace/release:key.path=value. Can be repeated.")
+}
+
+// parseValueOverrides parses the value overrides given on the command line and verifies that each of them
+// targets a release within the configuration.  Overrides are returned in the order helm applies them.
+func parseValueOverrides(c *config.BinnacleConfig) ([]config.ValueOverride, error) {
+	var overrides []config.ValueOverride
+
+	flags := []struct {
+		kind   string
+		values []string
+	}{
+		{config.OverrideSet, setValues},
+		{config.OverrideSetString, setStringValues},
+		{config.OverrideSetFile, setFileValues},
+	}
+
+	for _, flag := range flags {
+		for _, s := range flag.values {
+			o, err := config.ParseValueOverride(flag.kind, s)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, err := c.FindChart(o.Release); err != nil {
+				return nil, err
+			}
+
+			overrides = append(overrides, o)
+		}
+	}
+
+	return overrides, nil
+}
+
+// applyValueOverrides merges the value overrides into the values of the charts they target
+func applyValueOverrides(c *config.BinnacleConfig) error {
+	overrides, err := parseValueOverrides(c)
+	if err != nil {
+		return err
+	}
+
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	for idx := range c.Charts {
+		chart := &c.Charts[idx]
+		chart.Values, _ = config.MergeValues(valuesLayers(c, *chart, overrides)...)
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"reflect"
+)
+
+// Placeholders used in place of sensitive data
+const (
+	redactedValue  = "<redacted>"
+	changedValue   = "<changed>"
+	unchangedValue = "<unchanged>"
+)
+
+// secretKind is the kind of resource that is always treated as sensitive
+const secretKind = "Secret"
+
+// publicFields are the top-level fields of sensitive resources that are never redacted
+var publicFields = map[string]bool{
+	"apiVersion": true,
+	"kind":       true,
+	"metadata":   true,
+	"type":       true,
+}
+
+// isSensitive returns if the data of the resource must be redacted
+func isSensitive(r Resource, sensitiveKinds []string) bool {
+	if r.Kind == secretKind {
+		return true
+	}
+
+	for _, kind := range sensitiveKinds {
+		if r.Kind == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactResources replaces the data of sensitive resources with placeholders.  When a resource exists in both
+// the current and desired resources, the placeholders show which values were changed and which were not.
+func redactResources(sensitiveKinds []string, current []Resource, desired []Resource) {
+	before := make(map[string]Resource)
+	for _, r := range current {
+		if isSensitive(r, sensitiveKinds) {
+			before[r.ID()] = r
+		}
+	}
+
+	for _, r := range desired {
+		if !isSensitive(r, sensitiveKinds) {
+			continue
+		}
+
+		if old, ok := before[r.ID()]; ok {
+			delete(before, r.ID())
+			redactObjects(old.Object, r.Object)
+		} else {
+			redactObject(r.Object)
+		}
+	}
+
+	// Whatever is left has been removed
+	for _, r := range before {
+		redactObject(r.Object)
+	}
+}
+
+// redactManifest replaces the data of all sensitive resources in a rendered manifest with placeholders
+func redactManifest(sensitiveKinds []string, resources []Resource) ([]Resource, error) {
+	res := make([]Resource, len(resources))
+
+	for i, r := range resources {
+		if isSensitive(r, sensitiveKinds) {
+			redactObject(r.Object)
+
+			data, err := r.YAML()
+			if err != nil {
+				return nil, err
+			}
+
+			r.Raw = data
+			if len(r.Source) > 0 {
+				r.Raw = "# Source: " + r.Source + "\n" + data
+			}
+		}
+		res[i] = r
+	}
+
+	return res, nil
+}
+
+func redactObject(obj map[string]any) {
+	for k, v := range obj {
+		if !publicFields[k] {
+			obj[k] = redactAll(v)
+		}
+	}
+}
+
+func redactObjects(before map[string]any, after map[string]any) {
+	for k := range before {
+		if _, ok := after[k]; !ok && !publicFields[k] {
+			before[k] = redactAll(before[k])
+		}
+	}
+
+	for k := range after {
+		if publicFields[k] {
+			continue
+		}
+
+		if _, ok := before[k]; ok {
+			before[k], after[k] = redactPair(before[k], after[k])
+		} else {
+			after[k] = redactAll(after[k])
+		}
+	}
+}
+
+// redactPair redacts a value that exists on both sides of a diff
+func redactPair(before any, after any) (any, any) {
+	beforeMap, beforeIsMap := before.(map[string]any)
+	afterMap, afterIsMap := after.(map[string]any)
+	if beforeIsMap && afterIsMap {
+		redactObjects(beforeMap, afterMap)
+		return beforeMap, afterMap
+	}
+
+	beforeList, beforeIsList := before.([]any)
+	afterList, afterIsList := after.([]any)
+	if beforeIsList && afterIsList {
+		for i := range beforeList {
+			if i < len(afterList) {
+				beforeList[i], afterList[i] = redactPair(beforeList[i], afterList[i])
+			} else {
+				beforeList[i] = redactAll(beforeList[i])
+			}
+		}
+		for i := len(beforeList); i < len(afterList); i++ {
+			afterList[i] = redactAll(afterList[i])
+		}
+		return beforeList, afterList
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return unchangedValue, unchangedValue
+	}
+	return redactedValue, changedValue
+}
+
+// redactAll replaces every value within the given value
+func redactAll(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			v[k] = redactAll(child)
+		}
+		return v
+	case []any:
+		for i, child := range v {
+			v[i] = redactAll(child)
+		}
+		return v
+	default:
+		return redactedValue
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactResources(t *testing.T) {
+	current, _ := ParseManifest(`apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+type: Opaque
+data:
+  password: b2xk
+  username: YWRtaW4=
+  token: dG9rZW4=
+`)
+	desired, _ := ParseManifest(`apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+type: Opaque
+data:
+  password: bmV3
+  username: YWRtaW4=
+  apiKey: a2V5
+`)
+
+	redactResources(nil, current, desired)
+
+	before := current[0].Object["data"].(map[string]any)
+	after := desired[0].Object["data"].(map[string]any)
+
+	tests := []struct {
+		data map[string]any
+		key  string
+		want string
+	}{
+		{before, "password", redactedValue},
+		{after, "password", changedValue},
+		{before, "username", unchangedValue},
+		{after, "username", unchangedValue},
+		{before, "token", redactedValue},
+		{after, "apiKey", redactedValue},
+	}
+
+	for _, tt := range tests {
+		if got := tt.data[tt.key]; got != tt.want {
+			t.Errorf("want %s to be %s, got %v", tt.key, tt.want, got)
+		}
+	}
+
+	if got := desired[0].Object["type"]; got != "Opaque" {
+		t.Errorf("want type to not be redacted, got %v", got)
+	}
+}
+
+func TestRedactManifest_SensitiveKinds(t *testing.T) {
+	resources, _ := ParseManifest(`# Source: app/templates/sealed.yaml
+apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  name: creds
+spec:
+  encryptedData:
+    password: AgBy3i4OJSWK
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+`)
+
+	got, err := redactManifest([]string{"SealedSecret"}, resources)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if strings.Contains(got[0].Raw, "AgBy3i4OJSWK") || !strings.HasPrefix(got[0].Raw, "# Source: app/templates/sealed.yaml\n") {
+		t.Errorf("want SealedSecret to be redacted, got:\n%s", got[0].Raw)
+	}
+
+	if !strings.Contains(got[1].Raw, "key: value") {
+		t.Errorf("want ConfigMap to not be redacted, got:\n%s", got[1].Raw)
+	}
+}
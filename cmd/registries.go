@@ -0,0 +1,62 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+// loginRegistries logs in to each configured OCI registry
+func loginRegistries(c *config.BinnacleConfig) error {
+	for _, registry := range c.Registries {
+		var cmdArgs []string
+
+		log.Debugf("Logging in to registry: %s", registry.LoginHost())
+
+		username, password, err := registry.Credentials(filepath.Dir(c.ConfigFile))
+		if err != nil {
+			return err
+		}
+
+		cmdArgs = append(cmdArgs, "registry")
+		cmdArgs = append(cmdArgs, "login")
+		cmdArgs = append(cmdArgs, registry.LoginHost())
+		cmdArgs = append(cmdArgs, "--username")
+		cmdArgs = append(cmdArgs, username)
+		cmdArgs = append(cmdArgs, "--password-stdin")
+
+		if registry.Insecure {
+			cmdArgs = append(cmdArgs, "--insecure")
+		}
+
+		res, err := RunHelmCommandWithInput(password, cmdArgs...)
+		if err != nil {
+			return fmt.Errorf("running helm registry login for %s: %s: %w", registry.LoginHost(), res.Stderr, err)
+		}
+		log.Debug(strings.TrimSpace(res.Stdout + res.Stderr))
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+func TestLoginRegistries_PasswordOnStdin(t *testing.T) {
+	t.Setenv("BINNACLE_TEST_REGISTRY_USERNAME", "deploy")
+	t.Setenv("BINNACLE_TEST_REGISTRY_PASSWORD", "s3cr3t")
+
+	calls := useFakeHelm(t, `cat > "$(dirname "$0")/stdin"`)
+
+	c := &config.BinnacleConfig{
+		ConfigFile: "binnacle.yml",
+		Registries: []config.RegistryConfig{{
+			Host:     "oci://registry.example.com",
+			Insecure: true,
+			Username: config.SecretRef{Env: "BINNACLE_TEST_REGISTRY_USERNAME"},
+			Password: config.SecretRef{Env: "BINNACLE_TEST_REGISTRY_PASSWORD"},
+		}},
+	}
+
+	if err := loginRegistries(c); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := []string{"registry login registry.example.com --username deploy --password-stdin --insecure"}
+	if got := helmCalls(t, calls); !reflect.DeepEqual(got, want) {
+		t.Errorf("want helm calls %q, got %q", want, got)
+	}
+
+	stdin, err := os.ReadFile(filepath.Join(filepath.Dir(calls), "stdin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(stdin) != "s3cr3t" {
+		t.Errorf("want the password on stdin, got %q", stdin)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// IndexEntry is a version of a chart listed in a repository index
+type IndexEntry struct {
+	AppVersion string `yaml:"appVersion"`
+	Digest     string `yaml:"digest"`
+	Name       string `yaml:"name"`
+	Version    string `yaml:"version"`
+}
+
+// RepoIndex is the index.yaml of a chart repository, as cached by helm
+type RepoIndex struct {
+	Entries map[string][]IndexEntry `yaml:"entries"`
+}
+
+// repositoryCacheDir returns the directory helm caches the repository indexes in
+func repositoryCacheDir() (string, error) {
+	res, err := RunHelmCommand("env", "HELM_REPOSITORY_CACHE")
+	if err != nil {
+		return "", fmt.Errorf("running helm env: %s: %w", res.Stderr, err)
+	}
+
+	return strings.TrimSpace(res.Stdout), nil
+}
+
+// loadRepoIndex reads the cached index of the given repository
+func loadRepoIndex(repo string) (*RepoIndex, error) {
+	dir, err := repositoryCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return readRepoIndex(filepath.Join(dir, repo+"-index.yaml"))
+}
+
+// readRepoIndex reads the repository index at the given path
+func readRepoIndex(path string) (*RepoIndex, error) {
+	var index RepoIndex
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading repository index: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("reading repository index %s: %w", path, err)
+	}
+
+	return &index, nil
+}
+
+// Versions returns the versions of the given chart, newest first.  Versions that are not valid semver are
+// left out.
+func (i *RepoIndex) Versions(chart string) []IndexEntry {
+	return sortVersions(i.Entries[chart])
+}
+
+// sortVersions returns the entries sorted newest first, leaving out versions that are not valid semver
+func sortVersions(all []IndexEntry) []IndexEntry {
+	var entries []IndexEntry
+	var versions = make(map[string]*semver.Version)
+
+	for _, entry := range all {
+		v, err := semver.NewVersion(entry.Version)
+		if err != nil {
+			continue
+		}
+		versions[entry.Version] = v
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(a, b int) bool {
+		return versions[entries[a].Version].GreaterThan(versions[entries[b].Version])
+	})
+
+	return entries
+}
+
+// Find returns the entry of the given chart version
+func (i *RepoIndex) Find(chart string, version string) (IndexEntry, bool) {
+	for _, entry := range i.Entries[chart] {
+		if entry.Version == version {
+			return entry, true
+		}
+	}
+
+	return IndexEntry{}, false
+}
+
+// Resolve returns the newest version of the chart that matches the semver constraint.  An empty constraint
+// matches the newest stable version, as helm does.
+func (i *RepoIndex) Resolve(chart string, constraint string) (IndexEntry, error) {
+	entry, err := resolveVersion(i.Versions(chart), constraint)
+	if err != nil {
+		return IndexEntry{}, fmt.Errorf("resolving version of chart %s: %w", chart, err)
+	}
+
+	return entry, nil
+}
+
+// resolveVersion returns the first of the sorted entries that matches the semver constraint
+func resolveVersion(entries []IndexEntry, constraint string) (IndexEntry, error) {
+	if len(constraint) == 0 {
+		constraint = "*"
+	}
+
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return IndexEntry{}, err
+	}
+
+	for _, entry := range entries {
+		v, _ := semver.NewVersion(entry.Version)
+		if c.Check(v) {
+			return entry, nil
+		}
+	}
+
+	return IndexEntry{}, fmt.Errorf("no version matches %s", constraint)
+}
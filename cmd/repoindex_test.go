@@ -0,0 +1,81 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRepoIndexVersions(t *testing.T) {
+	index, err := readRepoIndex("../testdata/repo-index.yaml")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	var got []string
+	for _, entry := range index.Versions("concourse") {
+		got = append(got, entry.Version)
+	}
+
+	want := []string{"3.0.0-rc.1", "2.1.0", "1.10.0", "1.3.4", "1.3.1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want versions %v, got %v", want, got)
+	}
+}
+
+func TestRepoIndexResolve(t *testing.T) {
+	index, err := readRepoIndex("../testdata/repo-index.yaml")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	tests := []struct {
+		constraint string
+		want       string
+	}{
+		{"", "2.1.0"},
+		{"~1.3", "1.3.4"},
+		{"^1.3", "1.10.0"},
+		{">=2.0 <3", "2.1.0"},
+		{">=3.0.0-0", "3.0.0-rc.1"},
+		{"1.3.1", "1.3.1"},
+	}
+
+	for _, test := range tests {
+		entry, err := index.Resolve("concourse", test.constraint)
+		if err != nil {
+			t.Errorf("want no error for %q, got %v", test.constraint, err)
+			continue
+		}
+		if entry.Version != test.want {
+			t.Errorf("want version %s for %q, got %s", test.want, test.constraint, entry.Version)
+		}
+	}
+
+	if _, err := index.Resolve("concourse", ">=4"); err == nil {
+		t.Errorf("want an error when no version matches, but was nil")
+	}
+
+	if _, err := index.Resolve("missing", ""); err == nil {
+		t.Errorf("want an error for a missing chart, but was nil")
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// Supported diff output formats
+const (
+	outputHTML     = "html"
+	outputMarkdown = "markdown"
+	outputText     = "text"
+)
+
+// DiffReport holds everything shown in a diff report
+type DiffReport struct {
+	Repositories []RepositoryAction
+	Releases     []ReleaseDiff
+}
+
+// ReleaseDiff holds the resource diffs of a single release
+type ReleaseDiff struct {
+	Namespace string
+	Release   string
+	Diffs     []ResourceDiff
+}
+
+// Name returns the namespace/release name of the release
+func (r ReleaseDiff) Name() string {
+	return r.Namespace + "/" + r.Release
+}
+
+// Count returns the number of resources with the given change
+func (r ReleaseDiff) Count(change string) int {
+	var count int
+	for _, diff := range r.Diffs {
+		if diff.Change == change {
+			count++
+		}
+	}
+	return count
+}
+
+var changeDescriptions = map[string]string{
+	ChangeAdded:   "has been added",
+	ChangeChanged: "has changed",
+	ChangeRemoved: "has been removed",
+}
+
+func validReportFormat(format string) bool {
+	switch format {
+	case outputHTML, outputMarkdown, outputText:
+		return true
+	default:
+		return false
+	}
+}
+
+// printTextDiff prints the diffs of a release to the terminal
+func printTextDiff(release ReleaseDiff) {
+	for _, diff := range release.Diffs {
+		fmt.Printf("%s %s:\n", diff.Resource, changeDescriptions[diff.Change])
+		fmt.Println(colorizeDiff(diff.Diff))
+	}
+}
+
+// colorizeDiff adds terminal colors to the lines of a unified diff
+func colorizeDiff(diff string) string {
+	if diffNoColor {
+		return strings.TrimSuffix(diff, "\n")
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			line = "\033[1m" + line + "\033[0m"
+		case strings.HasPrefix(line, "@@"):
+			line = "\033[36m" + line + "\033[0m"
+		case strings.HasPrefix(line, "+"):
+			line = "\033[32m" + line + "\033[0m"
+		case strings.HasPrefix(line, "-"):
+			line = "\033[31m" + line + "\033[0m"
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+var reportFuncs = map[string]any{
+	"added":       func() string { return ChangeAdded },
+	"changed":     func() string { return ChangeChanged },
+	"removed":     func() string { return ChangeRemoved },
+	"description": func(change string) string { return changeDescriptions[change] },
+	"fence":       codeFence,
+	"trim":        func(s string) string { return strings.TrimSuffix(s, "\n") },
+}
+
+var markdownReport = template.Must(template.New("markdown").Funcs(reportFuncs).Parse(`## Binnacle diff
+
+{{ if .Repositories -}}
+Repository changes:
+
+{{ range .Repositories -}}
+- ` + "`{{ . }}`" + `
+{{ end }}
+{{ end -}}
+| Release | Added | Changed | Removed |
+| --- | ---: | ---: | ---: |
+{{- range .Releases }}
+| {{ .Name }} | {{ .Count added }} | {{ .Count changed }} | {{ .Count removed }} |
+{{- end }}
+{{ range .Releases }}{{ if .Diffs }}
+<details>
+<summary>{{ .Name }}: {{ .Count added }} added, {{ .Count changed }} changed, {{ .Count removed }} removed</summary>
+{{ range .Diffs }}
+**{{ .Resource }}** {{ description .Change }}
+
+{{ $fence := fence .Diff }}{{ $fence }}diff
+{{ trim .Diff }}
+{{ $fence }}
+{{ end }}
+</details>
+{{ end }}{{ end }}`))
+
+var htmlReport = htmltemplate.Must(htmltemplate.New("html").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Binnacle diff</title>
+</head>
+<body>
+<h2>Binnacle diff</h2>
+{{- if .Repositories }}
+<p>Repository changes:</p>
+<ul>
+{{- range .Repositories }}
+<li><code>{{ . }}</code></li>
+{{- end }}
+</ul>
+{{- end }}
+<table>
+<thead>
+<tr><th>Release</th><th>Added</th><th>Changed</th><th>Removed</th></tr>
+</thead>
+<tbody>
+{{- range .Releases }}
+<tr><td>{{ .Name }}</td><td>{{ .Count added }}</td><td>{{ .Count changed }}</td><td>{{ .Count removed }}</td></tr>
+{{- end }}
+</tbody>
+</table>
+{{- range .Releases }}{{ if .Diffs }}
+<details>
+<summary>{{ .Name }}: {{ .Count added }} added, {{ .Count changed }} changed, {{ .Count removed }} removed</summary>
+{{- range .Diffs }}
+<p><strong>{{ .Resource }}</strong> {{ description .Change }}</p>
+<pre><code class="language-diff">{{ trim .Diff }}</code></pre>
+{{- end }}
+</details>
+{{- end }}{{ end }}
+</body>
+</html>
+`))
+
+// writeDiffReport writes the repository changes and the diffs of all releases in the given report format
+func writeDiffReport(w io.Writer, format string, report DiffReport) error {
+	var err error
+
+	switch format {
+	case outputMarkdown:
+		err = markdownReport.Execute(w, report)
+	case outputHTML:
+		err = htmlReport.Execute(w, report)
+	default:
+		err = fmt.Errorf("unsupported format %q", format)
+	}
+
+	if err != nil {
+		return fmt.Errorf("writing diff report: %w", err)
+	}
+
+	return nil
+}
+
+// codeFence returns a markdown code fence that is longer than any run of backticks in the content
+func codeFence(content string) string {
+	var longest, current int
+	for _, r := range content {
+		if r == '`' {
+			current++
+			if current > longest {
+				longest = current
+			}
+		} else {
+			current = 0
+		}
+	}
+
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
@@ -0,0 +1,101 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+func testDiffReport(t *testing.T) DiffReport {
+	current, _ := ParseManifest(currentManifest)
+	desired, _ := ParseManifest(desiredManifest)
+
+	diffs, err := DiffResources(current, desired)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	return DiffReport{
+		Repositories: []RepositoryAction{
+			{Action: RepoAdd, Repository: config.RepositoryConfig{Name: "stable", URL: "https://charts.example.com"}},
+		},
+		Releases: []ReleaseDiff{
+			{Namespace: "apps", Release: "web", Diffs: diffs},
+			{Namespace: "apps", Release: "worker"},
+		},
+	}
+}
+
+func TestWriteDiffReport_Markdown(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := writeDiffReport(&buf, outputMarkdown, testDiffReport(t)); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		"- `+ add repository stable (https://charts.example.com)`",
+		"| apps/web | 1 | 1 | 0 |",
+		"| apps/worker | 0 | 0 | 0 |",
+		"<summary>apps/web: 1 added, 1 changed, 0 removed</summary>",
+		"```diff\n--- current\n+++ desired\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want markdown report to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	if strings.Contains(got, "apps/worker:") {
+		t.Errorf("want no section for a release without changes, got:\n%s", got)
+	}
+}
+
+func TestWriteDiffReport_HTML(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := writeDiffReport(&buf, outputHTML, testDiffReport(t)); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	got := buf.String()
+
+	want := `<tr><td>apps/web</td><td>1</td><td>1</td><td>0</td></tr>`
+	if !strings.Contains(got, want) {
+		t.Errorf("want html report to contain %q, got:\n%s", want, got)
+	}
+
+	want = `&#43;  replicas: &#34;3&#34;`
+	if !strings.Contains(got, want) {
+		t.Errorf("want html report to escape the diff, got:\n%s", got)
+	}
+}
+
+func TestCodeFence(t *testing.T) {
+	got := codeFence("a ```` b")
+	want := "`````"
+	if got != want {
+		t.Errorf("want fence %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,320 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Traackr/binnacle/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Actions taken to reconcile the helm repositories with the configuration
+const (
+	RepoAdd    = "add"
+	RepoRemove = "remove"
+	RepoUpdate = "update"
+)
+
+// RepositoryAction is a single change to the helm repositories
+type RepositoryAction struct {
+	Action     string
+	Repository config.RepositoryConfig
+	// CurrentURL is the URL of the repository before an update
+	CurrentURL string
+}
+
+func (a RepositoryAction) String() string {
+	switch a.Action {
+	case RepoAdd:
+		return fmt.Sprintf("+ add repository %s (%s)", a.Repository.Name, a.Repository.URL)
+	case RepoRemove:
+		return fmt.Sprintf("- remove repository %s (%s)", a.Repository.Name, a.CurrentURL)
+	default:
+		if a.CurrentURL == a.Repository.URL {
+			return fmt.Sprintf("~ update repository %s (credentials or TLS settings changed)", a.Repository.Name)
+		}
+		return fmt.Sprintf("~ update repository %s (%s -> %s)", a.Repository.Name, a.CurrentURL, a.Repository.URL)
+	}
+}
+
+// RepositoryPlan holds the changes needed to reconcile the helm repositories with the configuration
+type RepositoryPlan struct {
+	Actions []RepositoryAction
+	// Refresh holds the names of the repositories whose index is updated because charts use them
+	Refresh []string
+}
+
+// planRepositories compares the configured repositories with the current helm repositories.  Repositories whose
+// URL, credentials or TLS settings differ are updated.  Relative file paths are resolved against the given
+// directory.
+func planRepositories(repos []config.RepositoryConfig, current []config.HelmRepository, charts []config.ChartConfig, dir string) (RepositoryPlan, error) {
+	var plan RepositoryPlan
+
+	available := make(map[string]bool)
+	for _, repo := range current {
+		available[repo.Name] = true
+	}
+
+	for _, repo := range repos {
+		var currentURL string
+		for _, r := range current {
+			if r.Name == repo.Name {
+				currentURL = r.URL
+			}
+		}
+
+		// Absent repositories are matched by name, as their credentials may no longer be available
+		if repo.State != config.StatePresent {
+			if available[repo.Name] {
+				plan.Actions = append(plan.Actions, RepositoryAction{Action: RepoRemove, Repository: repo, CurrentURL: currentURL})
+				delete(available, repo.Name)
+			}
+			continue
+		}
+
+		desired, err := repo.HelmRepository(dir)
+		if err != nil {
+			return RepositoryPlan{}, err
+		}
+
+		exists, fullMatch := repoExists(desired, current)
+
+		switch {
+		case !exists:
+			plan.Actions = append(plan.Actions, RepositoryAction{Action: RepoAdd, Repository: repo})
+			available[repo.Name] = true
+		case !fullMatch:
+			plan.Actions = append(plan.Actions, RepositoryAction{Action: RepoUpdate, Repository: repo, CurrentURL: currentURL})
+		}
+	}
+
+	plan.Refresh = usedRepositories(charts, available)
+
+	return plan, nil
+}
+
+// usedRepositories returns the sorted names of the available repositories that are used by present charts.
+// Only their index is refreshed.  OCI charts are pulled from their registry and never use a repository.
+func usedRepositories(charts []config.ChartConfig, available map[string]bool) []string {
+	var names []string
+
+	used := make(map[string]bool)
+	for _, chart := range charts {
+		if chart.IsOCI() {
+			continue
+		}
+
+		if chart.State == config.StatePresent && available[chart.Repo] && !used[chart.Repo] {
+			used[chart.Repo] = true
+			names = append(names, chart.Repo)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Empty returns if the plan does not change any repositories
+func (p RepositoryPlan) Empty() bool {
+	return len(p.Actions) == 0
+}
+
+// printRepositoryPlan prints the changes of the plan
+func printRepositoryPlan(plan RepositoryPlan) {
+	if plan.Empty() {
+		fmt.Println("Repositories are up to date.")
+		return
+	}
+
+	fmt.Println("Repository changes:")
+	for _, action := range plan.Actions {
+		fmt.Printf("  %s\n", action)
+	}
+}
+
+// applyRepositoryPlan runs the helm commands for each action of the plan, followed by a repository update
+// for the repositories used by charts
+func applyRepositoryPlan(plan RepositoryPlan, configFile string) error {
+	for _, action := range plan.Actions {
+		var cmdArgs []string
+		var err error
+		var res Result
+
+		log.Debugf("Processing repo: %s", action)
+
+		switch action.Action {
+		case RepoRemove:
+			cmdArgs = append(cmdArgs, "repo")
+			cmdArgs = append(cmdArgs, "remove")
+			cmdArgs = append(cmdArgs, action.Repository.Name)
+
+			res, err = RunHelmCommand(cmdArgs...)
+			if err != nil {
+				return fmt.Errorf("running helm repo remove: %s: %w", res.Stderr, err)
+			}
+		default:
+			cmdArgs = append(cmdArgs, "repo")
+			cmdArgs = append(cmdArgs, "add")
+			cmdArgs = append(cmdArgs, action.Repository.Name)
+			cmdArgs = append(cmdArgs, action.Repository.URL)
+
+			username, password, err := action.Repository.Credentials(filepath.Dir(configFile))
+			if err != nil {
+				return err
+			}
+			cmdArgs = append(cmdArgs, action.Repository.AddArgs(filepath.Dir(configFile), username, password)...)
+
+			if action.Action == RepoUpdate {
+				cmdArgs = append(cmdArgs, "--force-update")
+			}
+
+			res, err = RunHelmCommandWithInput(password, cmdArgs...)
+			if err != nil {
+				return fmt.Errorf("running helm repo add: %s: %w", res.Stderr, err)
+			}
+		}
+
+		// Logged to stderr, so that it does not end up in reports or other output written to stdout
+		log.Info(strings.TrimSpace(res.Stdout))
+	}
+
+	return updateRepositories(plan.Refresh)
+}
+
+// updateRepositories refreshes the index of the given repositories
+func updateRepositories(names []string) error {
+	var cmdArgs []string
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	cmdArgs = append(cmdArgs, "repo")
+	cmdArgs = append(cmdArgs, "update")
+	cmdArgs = append(cmdArgs, names...)
+
+	res, err := RunHelmCommand(cmdArgs...)
+	if err != nil {
+		return fmt.Errorf("running helm repo update: %s: %w", res.Stderr, err)
+	}
+	log.Debug(strings.TrimSpace(res.Stdout))
+
+	return nil
+}
+
+// refreshRepositories refreshes the index of the repositories used by charts, without adding, updating or
+// removing any repository.  Repositories that have not been added yet are left out.
+func refreshRepositories(c *config.BinnacleConfig) error {
+	current, err := getCurrentRepositories()
+	if err != nil {
+		return err
+	}
+
+	available := make(map[string]bool)
+	for _, repo := range current {
+		available[repo.Name] = true
+	}
+
+	return updateRepositories(usedRepositories(c.Charts, available))
+}
+
+// syncRepositories reconciles the helm repositories with the configuration, logs in to the OCI registries, and
+// returns the plan that was applied
+func syncRepositories(c *config.BinnacleConfig) (RepositoryPlan, error) {
+	plan, err := getRepositoryPlan(c)
+	if err != nil {
+		return plan, err
+	}
+
+	if err := applyRepositoryPlan(plan, c.ConfigFile); err != nil {
+		return plan, err
+	}
+
+	return plan, loginRegistries(c)
+}
+
+// getRepositoryPlan computes the changes needed to reconcile the helm repositories with the configuration
+func getRepositoryPlan(c *config.BinnacleConfig) (RepositoryPlan, error) {
+	current, err := getCurrentRepositories()
+	if err != nil {
+		return RepositoryPlan{}, err
+	}
+
+	return planRepositories(c.Repositories, current, c.Charts, filepath.Dir(c.ConfigFile))
+}
+
+// getCurrentRepositories returns the repositories helm has added.  They are read from the helm repositories file
+// rather than `helm repo list`, which leaves out the credentials and TLS settings.
+func getCurrentRepositories() ([]config.HelmRepository, error) {
+	res, err := RunHelmCommand("env", "HELM_REPOSITORY_CONFIG")
+	if err != nil {
+		return nil, fmt.Errorf("running helm env: %s: %w", res.Stderr, err)
+	}
+
+	return readHelmRepositories(strings.TrimSpace(res.Stdout))
+}
+
+// readHelmRepositories reads the helm repositories file at the given path.  The file does not exist until the
+// first repository is added.
+func readHelmRepositories(path string) ([]config.HelmRepository, error) {
+	var file struct {
+		Repositories []config.HelmRepository `yaml:"repositories"`
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading helm repositories: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("reading helm repositories %s: %w", path, err)
+	}
+
+	return file.Repositories, nil
+}
+
+// repoExists returns if a repository with the same name exists, and if it also has the same URL, credentials
+// and TLS settings
+func repoExists(repo config.HelmRepository, repos []config.HelmRepository) (bool, bool) {
+	var exists = false
+	var fullMatch = false
+
+	// Check if this repo already exists
+	for _, checkRepo := range repos {
+		if repo.Name == checkRepo.Name {
+			exists = true
+			fullMatch = repo == checkRepo
+			break
+		}
+	}
+
+	return exists, fullMatch
+}
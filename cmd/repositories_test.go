@@ -0,0 +1,307 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+func TestPlanRepositories(t *testing.T) {
+	repos := []config.RepositoryConfig{
+		{Name: "stable", State: config.StatePresent, URL: "https://charts.example.com/stable"},
+		{Name: "moved", State: config.StatePresent, URL: "https://charts.example.com/new"},
+		{Name: "unchanged", State: config.StatePresent, URL: "https://charts.example.com/unchanged"},
+		{Name: "old", State: "absent", URL: "https://charts.example.com/old"},
+		{Name: "gone", State: "absent", URL: "https://charts.example.com/gone"},
+	}
+	current := []config.HelmRepository{
+		{Name: "moved", URL: "https://charts.example.com/old-location"},
+		{Name: "unchanged", URL: "https://charts.example.com/unchanged"},
+		{Name: "old", URL: "https://charts.example.com/old"},
+		{Name: "other", URL: "https://charts.example.com/other"},
+	}
+	charts := []config.ChartConfig{
+		{Repo: "unchanged", State: config.StatePresent},
+		{Repo: "stable", State: config.StatePresent},
+		{Repo: "stable", State: config.StatePresent},
+		{Repo: "other", State: "absent"},
+		{Repo: "old", State: config.StatePresent},
+	}
+
+	plan, err := planRepositories(repos, current, charts, ".")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	wantActions := []string{
+		"+ add repository stable (https://charts.example.com/stable)",
+		"~ update repository moved (https://charts.example.com/old-location -> https://charts.example.com/new)",
+		"- remove repository old (https://charts.example.com/old)",
+	}
+	var gotActions []string
+	for _, action := range plan.Actions {
+		gotActions = append(gotActions, action.String())
+	}
+	if !reflect.DeepEqual(gotActions, wantActions) {
+		t.Errorf("want actions %q, got %q", wantActions, gotActions)
+	}
+
+	wantRefresh := []string{"stable", "unchanged"}
+	if !reflect.DeepEqual(plan.Refresh, wantRefresh) {
+		t.Errorf("want refresh %q, got %q", wantRefresh, plan.Refresh)
+	}
+}
+
+func TestPlanRepositoriesUpToDate(t *testing.T) {
+	repos := []config.RepositoryConfig{
+		{Name: "stable", State: config.StatePresent, URL: "https://charts.example.com/stable"},
+	}
+	current := []config.HelmRepository{
+		{Name: "stable", URL: "https://charts.example.com/stable"},
+	}
+
+	plan, err := planRepositories(repos, current, nil, ".")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if !plan.Empty() {
+		t.Errorf("want empty plan, got %v", plan.Actions)
+	}
+	if len(plan.Refresh) != 0 {
+		t.Errorf("want no repositories to refresh, got %q", plan.Refresh)
+	}
+}
+
+func TestPlanRepositoriesChangedSettings(t *testing.T) {
+	t.Setenv("BINNACLE_TEST_REPO_USERNAME", "deploy")
+	t.Setenv("BINNACLE_TEST_REPO_PASSWORD", "rotated")
+
+	repos := []config.RepositoryConfig{
+		{
+			Name:     "private",
+			State:    config.StatePresent,
+			URL:      "https://charts.example.com/private",
+			Username: config.SecretRef{Env: "BINNACLE_TEST_REPO_USERNAME"},
+			Password: config.SecretRef{Env: "BINNACLE_TEST_REPO_PASSWORD"},
+		},
+		{Name: "tls", State: config.StatePresent, URL: "https://charts.example.com/tls", CAFile: "/etc/ssl/ca.pem"},
+		{Name: "plain", State: config.StatePresent, URL: "https://charts.example.com/plain"},
+		{Name: "old", State: "absent", Password: config.SecretRef{Env: "BINNACLE_TEST_UNSET_VARIABLE"}},
+	}
+	current := []config.HelmRepository{
+		{Name: "private", URL: "https://charts.example.com/private", Username: "deploy", Password: "s3cr3t"},
+		{Name: "tls", URL: "https://charts.example.com/tls"},
+		{Name: "plain", URL: "https://charts.example.com/plain", InsecureSkipTLSVerify: true},
+		{Name: "old", URL: "https://charts.example.com/old"},
+	}
+
+	plan, err := planRepositories(repos, current, nil, ".")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := []string{
+		"~ update repository private (credentials or TLS settings changed)",
+		"~ update repository tls (credentials or TLS settings changed)",
+		"~ update repository plain (credentials or TLS settings changed)",
+		"- remove repository old (https://charts.example.com/old)",
+	}
+	var got []string
+	for _, action := range plan.Actions {
+		got = append(got, action.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want actions %q, got %q", want, got)
+	}
+}
+
+func TestReadHelmRepositories(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "repositories.yaml")
+
+	repos, err := readHelmRepositories(path)
+	if err != nil || repos != nil {
+		t.Errorf("want no repositories for a missing file, got %v, %v", repos, err)
+	}
+
+	data := `apiVersion: ""
+generated: "0001-01-01T00:00:00Z"
+repositories:
+- caFile: /etc/ssl/ca.pem
+  certFile: ""
+  insecure_skip_tls_verify: false
+  keyFile: ""
+  name: private
+  pass_credentials_all: true
+  password: s3cr3t
+  url: https://charts.example.com/private
+  username: deploy
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repos, err = readHelmRepositories(path)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := []config.HelmRepository{{
+		CAFile:          "/etc/ssl/ca.pem",
+		Name:            "private",
+		PassCredentials: true,
+		Password:        "s3cr3t",
+		URL:             "https://charts.example.com/private",
+		Username:        "deploy",
+	}}
+	if !reflect.DeepEqual(repos, want) {
+		t.Errorf("want %#v, got %#v", want, repos)
+	}
+}
+
+func TestRepoExists(t *testing.T) {
+	repos := []config.HelmRepository{
+		{Name: "first", URL: "https://charts.example.com/first"},
+		{Name: "second", URL: "https://charts.example.com/second"},
+	}
+
+	tests := []struct {
+		repo      config.HelmRepository
+		exists    bool
+		fullMatch bool
+	}{
+		{config.HelmRepository{Name: "second", URL: "https://charts.example.com/second"}, true, true},
+		{config.HelmRepository{Name: "second", URL: "https://charts.example.com/other"}, true, false},
+		{config.HelmRepository{Name: "second", URL: "https://charts.example.com/second", Password: "s3cr3t"}, true, false},
+		{config.HelmRepository{Name: "third", URL: "https://charts.example.com/second"}, false, false},
+	}
+
+	for _, test := range tests {
+		exists, fullMatch := repoExists(test.repo, repos)
+		if exists != test.exists || fullMatch != test.fullMatch {
+			t.Errorf("want %v, %v for %s, got %v, %v", test.exists, test.fullMatch, test.repo.Name, exists, fullMatch)
+		}
+	}
+}
+
+func TestApplyRepositoryPlan_PasswordOnStdin(t *testing.T) {
+	t.Setenv("BINNACLE_TEST_REPO_USERNAME", "deploy")
+	t.Setenv("BINNACLE_TEST_REPO_PASSWORD", "s3cr3t")
+
+	calls := useFakeHelm(t, `if [ "$2" = add ]; then cat > "$(dirname "$0")/stdin"; echo "\"$3\" has been added to your repositories"; fi`)
+
+	repo := config.RepositoryConfig{
+		Name:     "private",
+		State:    config.StatePresent,
+		URL:      "https://charts.example.com/private",
+		Username: config.SecretRef{Env: "BINNACLE_TEST_REPO_USERNAME"},
+		Password: config.SecretRef{Env: "BINNACLE_TEST_REPO_PASSWORD"},
+	}
+	plan := RepositoryPlan{Actions: []RepositoryAction{{Action: RepoAdd, Repository: repo}}}
+
+	var err error
+	stdout := captureStdout(t, func() {
+		err = applyRepositoryPlan(plan, "binnacle.yml")
+	})
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	// Reports and values are written to stdout, so the output of helm is kept out of it
+	if len(stdout) > 0 {
+		t.Errorf("want nothing written to stdout, got %q", stdout)
+	}
+
+	want := []string{"repo add private https://charts.example.com/private --username deploy --password-stdin"}
+	if got := helmCalls(t, calls); !reflect.DeepEqual(got, want) {
+		t.Errorf("want helm calls %q, got %q", want, got)
+	}
+
+	stdin, err := os.ReadFile(filepath.Join(filepath.Dir(calls), "stdin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(stdin) != "s3cr3t" {
+		t.Errorf("want the password on stdin, got %q", stdin)
+	}
+}
+
+func TestRefreshRepositories(t *testing.T) {
+	repositories := filepath.Join(t.TempDir(), "repositories.yaml")
+	data := "repositories:\n- name: stable\n  url: https://charts.example.com/stable\n- name: other\n  url: https://charts.example.com/other\n"
+	if err := os.WriteFile(repositories, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := useFakeHelm(t, `if [ "$1" = env ]; then echo `+repositories+`; fi`)
+
+	c := &config.BinnacleConfig{
+		Repositories: []config.RepositoryConfig{
+			{Name: "stable", State: config.StatePresent, URL: "https://charts.example.com/moved"},
+			{Name: "missing", State: config.StatePresent, URL: "https://charts.example.com/missing"},
+			{Name: "other", State: "absent"},
+		},
+		Charts: []config.ChartConfig{
+			{Repo: "stable", State: config.StatePresent},
+			{Repo: "missing", State: config.StatePresent},
+		},
+	}
+
+	if err := refreshRepositories(c); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	// Only the index is refreshed, no repository is added, updated or removed
+	want := []string{"env HELM_REPOSITORY_CONFIG", "repo update stable"}
+	if got := helmCalls(t, calls); !reflect.DeepEqual(got, want) {
+		t.Errorf("want helm calls %q, got %q", want, got)
+	}
+}
+
+// captureStdout returns what the given function writes to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	w.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
@@ -22,6 +22,7 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -38,6 +39,12 @@ import (
 
 var cfgFile string
 
+// configNotRequired is the annotation of commands that run without a Binnacle config file
+const configNotRequired = "binnacle/config-not-required"
+
+// helmEnv holds the environment variables set for every helm command, in addition to the current environment
+var helmEnv []string
+
 // log The general purpose logging interface available to all commands
 var log = logrus.New()
 
@@ -49,35 +56,55 @@ var VERSION string
 
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
-	Use:          "binnacle",
-	Short:        "An opinionated automation tool for Kubernetes' Helm.",
-	Long:         ``,
-	SilenceUsage: true,
-	Version:      fmt.Sprintf("%s-%s", VERSION, GITCOMMIT),
+	Use:           "binnacle",
+	Short:         "An opinionated automation tool for Kubernetes' Helm.",
+	Long:          ``,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Version:       fmt.Sprintf("%s-%s", VERSION, GITCOMMIT),
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return initConfig(cmd)
+	},
 }
 
 // Execute adds all child commands to the root command sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	if err := RootCmd.Execute(); err != nil {
+		// A Result without any output only carries the exit code for the command
+		var res *Result
+		if errors.As(err, &res) && len(res.Stderr) == 0 {
+			os.Exit(res.ExitCode)
+		}
+
+		fmt.Fprintln(os.Stderr, "Error:", err)
 		os.Exit(1)
 	}
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
 	// General Flags
 	RootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "The Binnacle config file (required)")
-	RootCmd.MarkFlagRequired("config")
 
 	// Logging Flags
 	RootCmd.PersistentFlags().String("loglevel", "info", "The level of logging. Acceptable values: debug, info, warn, error, fatal, panic.")
 	viper.BindPFlag("loglevel", RootCmd.PersistentFlags().Lookup("loglevel"))
+
+	// Helm Flags
+	RootCmd.PersistentFlags().Bool("isolate-repositories", false, "Use a helm repository configuration and cache dedicated to the Binnacle config file.")
+	viper.BindPFlag("isolateRepositories", RootCmd.PersistentFlags().Lookup("isolate-repositories"))
 }
 
-func initConfig() {
+// initConfig reads the config file before any command runs.  The config flag is required, except for the commands
+// annotated with configNotRequired.
+func initConfig(cmd *cobra.Command) error {
+	if _, found := cmd.Annotations[configNotRequired]; found {
+		initLogger()
+		return nil
+	}
+
 	if cfgFile == "" {
-		log.Fatal("no configuration file specified")
+		return fmt.Errorf(`required flag(s) "config" not set`)
 	}
 
 	viper.SetConfigFile(cfgFile)
@@ -89,13 +116,43 @@ func initConfig() {
 		log.Fatalf("Failed to load configuration file '%s': %v", viper.ConfigFileUsed(), err)
 	}
 
-	fmt.Println("Loaded config file:", viper.ConfigFileUsed())
+	// Written to stderr, so that it does not end up in json or yaml output
+	fmt.Fprintln(os.Stderr, "Loaded config file:", viper.ConfigFileUsed())
+
+	initLogger()
+
+	return nil
+}
 
-	// Initialize the logger for all commands to use
+// initLogger initializes the logger for all commands to use
+func initLogger() {
 	logLevel, _ := logrus.ParseLevel(viper.GetString("loglevel"))
 	log.Level = logLevel
 	log.Debug("Logger initialized.")
+}
 
+// loadConfig loads the Binnacle configuration and prepares the helm environment for it
+func loadConfig() (*config.BinnacleConfig, error) {
+	c, err := config.LoadAndValidateFromViper()
+	if err != nil {
+		return nil, err
+	}
+
+	helmEnv = nil
+
+	if c.IsolateRepositories {
+		dir, err := configCacheDir(c.ConfigFile)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Debugf("Using isolated helm repositories in %s", dir)
+
+		helmEnv = append(helmEnv, "HELM_REPOSITORY_CONFIG="+filepath.Join(dir, "repositories.yaml"))
+		helmEnv = append(helmEnv, "HELM_REPOSITORY_CACHE="+filepath.Join(dir, "repository"))
+	}
+
+	return c, nil
 }
 
 // PluginInstalled returns if the given plugin is installed
@@ -135,8 +192,9 @@ func PluginInstalled(plugin string) (bool, error) {
 	return false, nil
 }
 
-func ReleaseExists(namespace string, release string, args ...string) bool {
-	var exists = true
+// ReleaseExists returns if the release is deployed.  Only a release that helm can not find is missing, every
+// other failure, such as an unreachable cluster, is returned as an error.
+func ReleaseExists(namespace string, release string, args ...string) (bool, error) {
 	var err error
 	var res Result
 	var cmdArgs []string
@@ -150,16 +208,31 @@ func ReleaseExists(namespace string, release string, args ...string) bool {
 	// Get the status of the release for the namespace
 	res, err = RunHelmCommand(cmdArgs...)
 	if err != nil {
-		if res.Stderr != "Error: release: not found" {
-			exists = false
+		if strings.Contains(res.Stderr, "release: not found") {
+			return false, nil
 		}
+		return false, fmt.Errorf("running helm status for release %s: %s: %w", release, res.Stderr, err)
 	}
 
-	return exists
+	return true, nil
+}
+
+// chartReleaseExists returns if the release of the chart is deployed, passing the chart's extra arguments for
+// `helm status`, such as a kube-context
+func chartReleaseExists(chart config.ChartConfig, args ...string) (bool, error) {
+	statusArgs := append(append([]string{}, args...), chart.HelmArgs("status")...)
+
+	return ReleaseExists(chart.Namespace, chart.Release, statusArgs...)
 }
 
 // RunHelmCommand runs the given command against helm
 func RunHelmCommand(args ...string) (Result, error) {
+	return RunHelmCommandWithInput("", args...)
+}
+
+// RunHelmCommandWithInput runs helm with the given input on stdin, which keeps secrets such as passwords off the
+// command line
+func RunHelmCommandWithInput(input string, args ...string) (Result, error) {
 	var result Result
 	var outbuf, errbuf bytes.Buffer
 
@@ -168,9 +241,16 @@ func RunHelmCommand(args ...string) (Result, error) {
 		return result, fmt.Errorf("searching for helm on PATH: %w", err)
 	}
 	cmd := exec.Command(helm, args...)
+	cmd.Env = append(os.Environ(), helmEnv...)
 
 	log.Debugf("Executing command:  %v", cmd.Args)
+	if len(helmEnv) > 0 {
+		log.Debugf("Using environment: %v", helmEnv)
+	}
 
+	if len(input) > 0 {
+		cmd.Stdin = strings.NewReader(input)
+	}
 	cmd.Stdout = &outbuf
 	cmd.Stderr = &errbuf
 
@@ -197,141 +277,6 @@ func RunHelmCommand(args ...string) (Result, error) {
 	return result, err
 }
 
-func syncRepositories(repos []config.RepositoryConfig, args ...string) error {
-	var reposModified = false
-
-	for _, repo := range repos {
-		var cmdArgs []string
-		var err error
-		var res Result
-		var currentRepos []config.RepositoryConfig
-
-		log.Debugf("Processing repo: %s", repo.Name)
-
-		currentRepos, err = getCurrentRepositories()
-		if err != nil {
-			return err
-		}
-
-		repoExists, repoFullMatch := repoExists(repo, currentRepos)
-
-		// If the repo exists and is not a full match, or we are deleting the repo - we need to delete the repo
-		if repoExists && (!repoFullMatch || repo.State != config.StatePresent) {
-			cmdArgs = append(cmdArgs, "repo")
-			cmdArgs = append(cmdArgs, "remove")
-			cmdArgs = append(cmdArgs, args...)
-			cmdArgs = append(cmdArgs, repo.Name)
-
-			res, err = RunHelmCommand(cmdArgs...)
-			if err != nil {
-				return fmt.Errorf("running helm repo remove: %s: %w", res.Stderr, err)
-			}
-		}
-		cmdArgs = nil
-
-		if repo.State == config.StatePresent {
-			cmdArgs = append(cmdArgs, "repo")
-			cmdArgs = append(cmdArgs, "add")
-			cmdArgs = append(cmdArgs, args...)
-			cmdArgs = append(cmdArgs, repo.Name)
-			cmdArgs = append(cmdArgs, repo.URL)
-
-			res, err = RunHelmCommand(cmdArgs...)
-			if err != nil {
-				return fmt.Errorf("running helm repo add: %s: %w", res.Stderr, err)
-			}
-			reposModified = true
-
-			fmt.Println(strings.TrimSpace(res.Stdout))
-		}
-	}
-
-	// If any repos have been added during this sync execute a helm repos update to update the cache.
-	if reposModified {
-		var cmdArgs []string
-		var err error
-		var res Result
-
-		cmdArgs = append(cmdArgs, "repo")
-		cmdArgs = append(cmdArgs, "update")
-		res, err = RunHelmCommand(cmdArgs...)
-		if err != nil {
-			return fmt.Errorf("running helm repo update: %s: %w", res.Stderr, err)
-		}
-		fmt.Println(strings.TrimSpace(res.Stdout))
-	}
-
-	return nil
-}
-
-func getCurrentRepositories() ([]config.RepositoryConfig, error) {
-	var err error
-	var output []string
-	var repos []config.RepositoryConfig
-	var res Result
-
-	// Get a list of currently configured repositories
-	res, err = RunHelmCommand("repo", "list")
-	if err != nil {
-		return nil, fmt.Errorf("running helm repo list: %s: %w", res.Stderr, err)
-	}
-
-	// Split the output on the new line
-	output = strings.Split(res.Stdout, "\n")
-
-	// Remove the column titles
-	if len(output) > 0 {
-		output = output[1:]
-	}
-
-	// Populate the repos
-	for _, line := range output {
-		var repo config.RepositoryConfig
-
-		if len(line) == 0 {
-			continue
-		}
-
-		// Split the string by a space
-		split := strings.Fields(line)
-
-		// Build the repository config
-		repo.Name = split[0]
-		repo.URL = split[1]
-
-		repos = append(repos, repo)
-	}
-
-	return repos, nil
-}
-
-func repoExists(repo config.RepositoryConfig, repos []config.RepositoryConfig) (bool, bool) {
-	var exists = false
-	var fullMatch = false
-
-	// Check if this repo already exists
-	for _, checkRepo := range repos {
-		// Check if the repos are equal
-		if repo.Equal(checkRepo) {
-			exists = true
-			fullMatch = true
-			break
-		} else {
-			// Check if their names match but URLs are different
-			if repo.Name == checkRepo.Name {
-				exists = true
-				if repo.URL != checkRepo.URL {
-					fullMatch = true
-				}
-				break
-			}
-		}
-
-	}
-
-	return exists, fullMatch
-}
-
 func SetupBinnacleWorkingDir() (string, error) {
 	dir, err := os.MkdirTemp("", "binnacle-exec")
 	if err != nil {
@@ -0,0 +1,84 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+func TestReleaseExists(t *testing.T) {
+	tests := []struct {
+		script  string
+		exists  bool
+		wantErr bool
+	}{
+		{script: `echo "STATUS: deployed"`, exists: true},
+		{script: `echo "Error: release: not found" >&2; exit 1`, exists: false},
+		{script: `echo "Error: Kubernetes cluster unreachable" >&2; exit 1`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		useFakeHelm(t, tt.script)
+
+		exists, err := ReleaseExists("apps", "web")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: want error %v, got %v", tt.script, tt.wantErr, err)
+		}
+		if exists != tt.exists {
+			t.Errorf("%s: want exists %v, got %v", tt.script, tt.exists, exists)
+		}
+	}
+}
+
+func TestChartReleaseExists_StatusArgs(t *testing.T) {
+	calls := useFakeHelm(t, `echo "STATUS: deployed"`)
+
+	chart := config.ChartConfig{
+		Namespace: "apps",
+		Release:   "web",
+		ExtraArgs: map[string][]string{"status": {"--kube-context", "prod"}},
+	}
+
+	if _, err := chartReleaseExists(chart, "--debug"); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := "status web --namespace apps --debug --kube-context prod"
+	if got := strings.Join(helmCalls(t, calls), "\n"); got != want {
+		t.Errorf("want helm %s, got helm %s", want, got)
+	}
+}
+
+func TestInitConfig_ConfigNotRequired(t *testing.T) {
+	defer func(file string) { cfgFile = file }(cfgFile)
+	cfgFile = ""
+
+	if err := initConfig(schemaCmd); err != nil {
+		t.Errorf("want schema to run without a config file, got %v", err)
+	}
+
+	if err := initConfig(syncCmd); err == nil {
+		t.Errorf("want an error for sync without a config file, but was nil")
+	}
+}
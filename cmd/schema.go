@@ -0,0 +1,63 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/Traackr/binnacle/config"
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Prints the JSON Schema of the Binnacle configuration file.",
+	Long: `Prints the JSON Schema of the Binnacle configuration file, which editors can use to autocomplete and
+validate config files.`,
+	Annotations: map[string]string{configNotRequired: "true"},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		schemaCmdPreRun()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return schemaCmdRun(args...)
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		schemaCmdPostRun()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(schemaCmd)
+}
+
+func schemaCmdPreRun() {
+	log.Debug("Executing `schema` command.")
+}
+
+func schemaCmdRun(args ...string) error {
+	_, err := os.Stdout.Write(config.Schema)
+	return err
+}
+
+func schemaCmdPostRun() {
+	log.Debug("Execution of the `schema` command has completed.")
+}
@@ -0,0 +1,202 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Traackr/binnacle/config"
+	"gopkg.in/yaml.v3"
+)
+
+// chartMetadata holds the fields of Chart.yaml and Chart.lock that binnacle needs
+type chartMetadata struct {
+	Dependencies []struct {
+		Alias   string `yaml:"alias"`
+		Name    string `yaml:"name"`
+		Version string `yaml:"version"`
+	} `yaml:"dependencies"`
+}
+
+// fetchCharts fetches the present charts that are not stored in a chart repository or registry, and points
+// them at their local copy.  Local charts are resolved against the directory of the config file.
+func fetchCharts(c *config.BinnacleConfig) error {
+	for idx := range c.Charts {
+		chart := &c.Charts[idx]
+
+		if chart.State != config.StatePresent {
+			continue
+		}
+
+		switch {
+		case !chart.Git.Empty():
+			dir, err := checkoutGitSource(chart.Git)
+			if err != nil {
+				return fmt.Errorf("fetching chart of release %s: %w", chart.Release, err)
+			}
+			chart.LocalChart = dir
+		case len(chart.URL) > 0:
+			file, err := downloadChart(chart.URL, chart.SHA256)
+			if err != nil {
+				return fmt.Errorf("fetching chart of release %s: %w", chart.Release, err)
+			}
+			chart.LocalChart = file
+		case chart.IsLocal():
+			chart.LocalChart = chart.Name
+			if !filepath.IsAbs(chart.LocalChart) {
+				chart.LocalChart = filepath.Join(filepath.Dir(c.ConfigFile), chart.Name)
+			}
+		default:
+			continue
+		}
+
+		// Packaged charts already include their dependencies
+		if info, err := os.Stat(chart.LocalChart); err != nil || !info.IsDir() {
+			continue
+		}
+
+		if err := buildChartDependencies(chart.LocalChart); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// buildChartDependencies runs `helm dependency build` for a local chart when its charts directory is out of
+// date
+func buildChartDependencies(dir string) error {
+	stale, err := staleDependencies(dir)
+	if err != nil {
+		return err
+	}
+
+	if len(stale) == 0 {
+		return nil
+	}
+
+	log.Debugf("Building dependencies of chart %s: out of date %s", dir, strings.Join(stale, ", "))
+
+	res, err := RunHelmCommand("dependency", "build", dir)
+	if err != nil {
+		return fmt.Errorf("running helm dependency build for %s: %s: %w", dir, res.Stderr, err)
+	}
+
+	return nil
+}
+
+// staleDependencies returns the dependencies of the local chart that are missing from its charts directory.
+// When the chart has a Chart.lock, the packaged dependencies must match the locked versions, and dependencies
+// missing from the lock are stale too, so that helm reports the lock as out of sync.
+func staleDependencies(dir string) ([]string, error) {
+	var stale []string
+
+	declared, err := readChartMetadata(filepath.Join(dir, "Chart.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := readChartMetadata(filepath.Join(dir, "Chart.lock"))
+	locked := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	versions := make(map[string]string)
+	for _, dependency := range lock.Dependencies {
+		versions[dependency.Name] = dependency.Version
+	}
+
+	for _, dependency := range declared.Dependencies {
+		version, found := versions[dependency.Name]
+
+		switch {
+		case locked && !found:
+			stale = append(stale, dependency.Name)
+		case locked:
+			if !dependencyPresent(dir, dependency.Name, version) {
+				stale = append(stale, dependency.Name)
+			}
+		default:
+			if !dependencyPresent(dir, dependency.Name, "") {
+				stale = append(stale, dependency.Name)
+			}
+		}
+	}
+
+	return stale, nil
+}
+
+// readChartMetadata reads the dependencies from the given Chart.yaml or Chart.lock
+func readChartMetadata(path string) (chartMetadata, error) {
+	var metadata chartMetadata
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return metadata, fmt.Errorf("reading chart metadata: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &metadata); err != nil {
+		return metadata, fmt.Errorf("reading chart metadata %s: %s", path, err)
+	}
+
+	return metadata, nil
+}
+
+// dependencyPresent returns if the dependency is packaged or unpacked in the charts directory of the chart.  An
+// empty version matches any version.
+func dependencyPresent(dir string, name string, version string) bool {
+	pattern := name + "-*.tgz"
+	if len(version) > 0 {
+		pattern = name + "-" + version + ".tgz"
+	}
+
+	if archives, _ := filepath.Glob(filepath.Join(dir, "charts", pattern)); len(archives) > 0 {
+		return true
+	}
+
+	unpacked := unpackedVersion(filepath.Join(dir, "charts", name))
+
+	return len(unpacked) > 0 && (len(version) == 0 || unpacked == version)
+}
+
+// unpackedVersion returns the version of the unpacked chart in the given directory, or an empty string if there
+// is none
+func unpackedVersion(dir string) string {
+	var metadata struct {
+		Version string `yaml:"version"`
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+	if err != nil {
+		return ""
+	}
+
+	if err := yaml.Unmarshal(data, &metadata); err != nil || len(metadata.Version) == 0 {
+		return ""
+	}
+
+	return metadata.Version
+}
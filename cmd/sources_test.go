@@ -0,0 +1,148 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+// writeChartFiles writes the given files, relative to dir
+func writeChartFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStaleDependencies_WithoutLock(t *testing.T) {
+	dir := t.TempDir()
+
+	chart := `apiVersion: v2
+name: demo
+version: 1.0.0
+dependencies:
+  - name: packaged
+    version: 1.0.0
+  - name: unpacked
+    version: 1.0.0
+  - name: missing
+    version: 1.0.0
+`
+	writeChartFiles(t, dir, map[string]string{
+		"Chart.yaml":                 chart,
+		"charts/packaged-1.0.0.tgz":  "",
+		"charts/unpacked/Chart.yaml": "name: unpacked\nversion: 1.0.0\n",
+		"charts/unrelated-1.0.0.tgz": "",
+		"charts/missing/values.yaml": "",
+	})
+
+	stale, err := staleDependencies(dir)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if len(stale) != 1 || stale[0] != "missing" {
+		t.Errorf("want stale dependencies [missing], got %v", stale)
+	}
+}
+
+func TestStaleDependencies_WithLock(t *testing.T) {
+	dir := t.TempDir()
+
+	writeChartFiles(t, dir, map[string]string{
+		"Chart.yaml": `apiVersion: v2
+name: demo
+version: 1.0.0
+dependencies:
+  - name: current
+    version: ~1.0.0
+  - name: outdated
+    version: ~2.0.0
+  - name: unpacked
+    version: ~3.0.0
+  - name: unlocked
+    version: ~4.0.0
+`,
+		"Chart.lock": `dependencies:
+  - name: current
+    version: 1.0.2
+  - name: outdated
+    version: 2.0.1
+  - name: unpacked
+    version: 3.0.0
+digest: sha256:0000
+`,
+		"charts/current-1.0.2.tgz":   "",
+		"charts/outdated-2.0.0.tgz":  "",
+		"charts/unpacked/Chart.yaml": "name: unpacked\nversion: 3.0.0\n",
+		"charts/unlocked-4.0.0.tgz":  "",
+	})
+
+	stale, err := staleDependencies(dir)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := []string{"outdated", "unlocked"}
+	if !reflect.DeepEqual(stale, want) {
+		t.Errorf("want stale dependencies %v, got %v", want, stale)
+	}
+}
+
+func TestFetchCharts_LocalPaths(t *testing.T) {
+	c := &config.BinnacleConfig{
+		ConfigFile: filepath.Join("deploy", "prod.yml"),
+		Charts: []config.ChartConfig{
+			{Name: "./charts/api", Release: "api", State: config.StatePresent},
+			{Name: "../packages/worker-1.0.0.tgz", Release: "worker", State: config.StatePresent},
+			{Name: "/srv/charts/cron", Release: "cron", State: config.StatePresent},
+			{Name: "concourse", Repo: "stable", Release: "concourse", State: config.StatePresent},
+		},
+	}
+
+	if err := fetchCharts(c); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := []string{
+		filepath.Join("deploy", "charts", "api"),
+		filepath.Join("packages", "worker-1.0.0.tgz"),
+		"/srv/charts/cron",
+		"stable/concourse",
+	}
+	for idx, chart := range c.Charts {
+		if got := chart.ChartURL(); got != want[idx] {
+			t.Errorf("want chart URL %s, got %s", want[idx], got)
+		}
+	}
+}
@@ -24,7 +24,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/Traackr/binnacle/config"
 	"github.com/spf13/cobra"
 )
 
@@ -55,7 +54,7 @@ func statusCmdPreRun() {
 func statusCmdRun(args ...string) error {
 
 	// Load our configuration
-	c, err := config.LoadAndValidateFromViper()
+	c, err := loadConfig()
 	if err != nil {
 		return err
 	}
@@ -80,6 +79,8 @@ func statusCmdRun(args ...string) error {
 			cmdArgs = append(cmdArgs, chart.Namespace)
 		}
 
+		cmdArgs = append(cmdArgs, chart.HelmArgs("status")...)
+
 		res, err = RunHelmCommand(cmdArgs...)
 		if err != nil {
 			return fmt.Errorf("running helm status for release %s: %w", chart.Release, err)
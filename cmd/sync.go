@@ -29,6 +29,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var syncDryRun bool
+
 // syncCmd represents the status command
 var syncCmd = &cobra.Command{
 	Use:   "sync",
@@ -47,6 +49,11 @@ var syncCmd = &cobra.Command{
 
 func init() {
 	RootCmd.AddCommand(syncCmd)
+
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Display the changes to repositories and releases without applying them.")
+
+	addValueOverrideFlags(syncCmd)
+	addUpdateFlag(syncCmd)
 }
 
 func syncCmdPreRun() {
@@ -55,13 +62,37 @@ func syncCmdPreRun() {
 
 func syncCmdRun(args ...string) error {
 	// Load our configuration
-	c, err := config.LoadAndValidateFromViper()
+	c, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
+	if err := applyValueOverrides(c); err != nil {
+		return err
+	}
+
+	if syncDryRun {
+		plan, err := getRepositoryPlan(c)
+		if err != nil {
+			return err
+		}
+
+		printRepositoryPlan(plan)
+		return printReleasePlan(c.Charts, args...)
+	}
+
 	// Sync repositories
-	if err := syncRepositories(c.Repositories, args...); err != nil {
+	if _, err := syncRepositories(c); err != nil {
+		return err
+	}
+
+	// Resolve chart versions
+	if err := resolveChartVersions(c, updateVersions); err != nil {
+		return err
+	}
+
+	// Fetch charts from other sources
+	if err := fetchCharts(c); err != nil {
 		return err
 	}
 
@@ -125,10 +156,17 @@ func syncCharts(charts []config.ChartConfig, configFile string, args ...string)
 				cmdArgs = append(cmdArgs, "--post-renderer")
 				cmdArgs = append(cmdArgs, postRenderExecutable)
 			}
+
+			cmdArgs = append(cmdArgs, args...)
+			cmdArgs = append(cmdArgs, chart.HelmArgs("upgrade")...)
 		} else {
 
 			// If the release does not exist do not attempt to delete the release
-			exists := ReleaseExists(chart.Namespace, chart.Release, args...)
+			exists, err := chartReleaseExists(chart, args...)
+			if err != nil {
+				return err
+			}
+
 			if !exists {
 				log.Infof("Skipping '%s/%s' as the release does not exist.", chart.Namespace, chart.Release)
 				continue
@@ -138,10 +176,10 @@ func syncCharts(charts []config.ChartConfig, configFile string, args ...string)
 			cmdArgs = append(cmdArgs, chart.Release)
 			cmdArgs = append(cmdArgs, "--namespace")
 			cmdArgs = append(cmdArgs, chart.Namespace)
+			cmdArgs = append(cmdArgs, args...)
+			cmdArgs = append(cmdArgs, chart.HelmArgs("uninstall")...)
 		}
 
-		cmdArgs = append(cmdArgs, args...)
-
 		res, err := RunHelmCommand(cmdArgs...)
 		if err != nil {
 			return fmt.Errorf("running helm sync for release %s: %s: %w", chart.Release, res.Stderr, err)
@@ -152,3 +190,31 @@ func syncCharts(charts []config.ChartConfig, configFile string, args ...string)
 
 	return nil
 }
+
+// printReleasePlan prints the helm command that a sync would run for each release
+func printReleasePlan(charts []config.ChartConfig, args ...string) error {
+	fmt.Println("Release changes:")
+
+	for _, chart := range charts {
+		exists, err := chartReleaseExists(chart, args...)
+		if err != nil {
+			return err
+		}
+
+		version := chart.Version
+		if len(version) == 0 {
+			version = "latest"
+		}
+
+		switch {
+		case chart.State == config.StatePresent && exists:
+			fmt.Printf("  ~ upgrade %s/%s (%s %s)\n", chart.Namespace, chart.Release, chart.ChartURL(), version)
+		case chart.State == config.StatePresent:
+			fmt.Printf("  + install %s/%s (%s %s)\n", chart.Namespace, chart.Release, chart.ChartURL(), version)
+		case exists:
+			fmt.Printf("  - uninstall %s/%s\n", chart.Namespace, chart.Release)
+		}
+	}
+
+	return nil
+}
@@ -29,6 +29,11 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var templateClean bool
+var templateFilter ResourceFilter
+var templateOutputDir string
+var templateShowSecrets bool
+
 // templateCmd represents the template command
 var templateCmd = &cobra.Command{
 	Use:   "template",
@@ -47,6 +52,16 @@ var templateCmd = &cobra.Command{
 
 func init() {
 	RootCmd.AddCommand(templateCmd)
+
+	templateCmd.Flags().BoolVar(&templateClean, "clean", false, "Remove files from the output directory that were not written by this run.")
+	templateCmd.Flags().StringSliceVar(&templateFilter.Kinds, "kind", nil, "Only output resources of the given kinds.")
+	templateCmd.Flags().StringSliceVar(&templateFilter.Names, "name", nil, "Only output resources with the given names. Glob patterns are supported.")
+	templateCmd.Flags().StringSliceVar(&templateFilter.Sources, "show-only", nil, "Only output resources rendered from the given chart templates, after post-rendering.")
+	templateCmd.Flags().StringVar(&templateOutputDir, "output-dir", "", "Write each resource to <output-dir>/<namespace>/<release>/<kind>-<name>.yaml instead of stdout.")
+	templateCmd.Flags().BoolVar(&templateShowSecrets, "show-secrets", false, "Show the data of Secrets and other sensitive resources instead of redacting it.")
+
+	addValueOverrideFlags(templateCmd)
+	addUpdateFlag(templateCmd)
 }
 
 func templateCmdPreRun() {
@@ -54,29 +69,48 @@ func templateCmdPreRun() {
 }
 
 func templateCmdRun(args ...string) error {
-	// Load our configuration
-	c, err := config.LoadAndValidateFromViper()
+	if templateClean && len(templateOutputDir) == 0 {
+		return fmt.Errorf("checking template flags: --clean requires --output-dir")
+	}
 
+	if templateClean && !templateFilter.Empty() {
+		return fmt.Errorf("checking template flags: --clean can not be combined with --kind, --name or --show-only")
+	}
+
+	// Load our configuration
+	c, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
+	if err := applyValueOverrides(c); err != nil {
+		return err
+	}
+
 	// Sync repositories
-	if err := syncRepositories(c.Repositories, args...); err != nil {
+	if _, err := syncRepositories(c); err != nil {
+		return err
+	}
+
+	// Resolve chart versions
+	if err := resolveChartVersions(c, updateVersions); err != nil {
+		return err
+	}
+
+	// Fetch charts from other sources
+	if err := fetchCharts(c); err != nil {
 		return err
 	}
 
 	var charts = c.Charts
 
 	var absentCharts []string
+	var writtenFiles = make(map[string]bool)
 
 	log.Debugf("Loaded %d charts.", len(charts))
 
 	// Iterate the charts in the config
 	for _, chart := range charts {
-		var cmdArgs []string
-		var res Result
-
 		log.Debugf("Processing chart: %s", chart.ChartURL())
 
 		// If the state is not set to present add the namespace/release to the not rendered list
@@ -85,65 +119,49 @@ func templateCmdRun(args ...string) error {
 			continue
 		}
 
-		// Create a temp working directory
-		dir, err := SetupBinnacleWorkingDir()
+		manifest, err := renderChart(chart, c.ConfigFile, args...)
 		if err != nil {
 			return err
 		}
-		defer os.RemoveAll(dir)
 
-		//
-		// Template out the charts values
-		//
-		valuesFile, err := chart.WriteValueFile(dir)
+		resources, err := ParseManifest(manifest)
 		if err != nil {
-			return err
+			return fmt.Errorf("reading rendered manifest for release %s: %w", chart.Release, err)
 		}
 
-		//
-		// Template against the chart
-		//
-		cmdArgs = nil
-
-		cmdArgs = append(cmdArgs, "template")
-
-		// NAME
-		cmdArgs = append(cmdArgs, chart.Release)
-
-		// CHART
-		cmdArgs = append(cmdArgs, chart.ChartURL())
-
-		// Add the namespace if given
-		if len(chart.Namespace) > 0 {
-			cmdArgs = append(cmdArgs, "--namespace")
-			cmdArgs = append(cmdArgs, chart.Namespace)
+		if len(templateFilter.Sources) > 0 && !chart.Kustomize.Empty() {
+			resources, err = assignRenderedSources(chart, c.ConfigFile, resources, args...)
+			if err != nil {
+				return err
+			}
 		}
 
-		cmdArgs = append(cmdArgs, "--values")
-		cmdArgs = append(cmdArgs, valuesFile)
-
-		if len(chart.Version) > 0 {
-			cmdArgs = append(cmdArgs, "--version")
-			cmdArgs = append(cmdArgs, chart.Version)
+		resources = templateFilter.Apply(resources)
+		if len(resources) == 0 {
+			continue
 		}
 
-		if !chart.Kustomize.Empty() {
-			postRenderExecutable, err := SetupKustomize(dir, c.ConfigFile, chart)
+		if !templateShowSecrets {
+			resources, err = redactManifest(c.SensitiveKinds, resources)
 			if err != nil {
 				return err
 			}
-			cmdArgs = append(cmdArgs, "--post-renderer")
-			cmdArgs = append(cmdArgs, postRenderExecutable)
 		}
 
-		cmdArgs = append(cmdArgs, args...)
-
-		res, err = RunHelmCommand(cmdArgs...)
-		if err != nil {
-			return fmt.Errorf("running helm template for release %s: %s: %w", chart.Release, res.Stderr, err)
+		if len(templateOutputDir) > 0 {
+			if err := writeResourceFiles(templateOutputDir, chart, resources, writtenFiles); err != nil {
+				return err
+			}
+			continue
 		}
 
-		fmt.Println(strings.TrimSpace(res.Stdout))
+		fmt.Println(formatManifest(resources))
+	}
+
+	if templateClean {
+		if err := removeStaleFiles(templateOutputDir, writtenFiles); err != nil {
+			return err
+		}
 	}
 
 	// Display output about the released that were not rendered
@@ -157,6 +175,99 @@ func templateCmdRun(args ...string) error {
 	return nil
 }
 
+// renderChart runs `helm template` for the given chart, including the kustomize post-renderer, and
+// returns the rendered manifest
+func renderChart(chart config.ChartConfig, configFile string, args ...string) (string, error) {
+	var cmdArgs []string
+	var res Result
+
+	// Create a temp working directory
+	dir, err := SetupBinnacleWorkingDir()
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	//
+	// Template out the charts values
+	//
+	valuesFile, err := chart.WriteValueFile(dir)
+	if err != nil {
+		return "", err
+	}
+
+	//
+	// Template against the chart
+	//
+	cmdArgs = append(cmdArgs, "template")
+
+	// NAME
+	cmdArgs = append(cmdArgs, chart.Release)
+
+	// CHART
+	cmdArgs = append(cmdArgs, chart.ChartURL())
+
+	// Add the namespace if given
+	if len(chart.Namespace) > 0 {
+		cmdArgs = append(cmdArgs, "--namespace")
+		cmdArgs = append(cmdArgs, chart.Namespace)
+	}
+
+	cmdArgs = append(cmdArgs, "--values")
+	cmdArgs = append(cmdArgs, valuesFile)
+
+	if len(chart.Version) > 0 {
+		cmdArgs = append(cmdArgs, "--version")
+		cmdArgs = append(cmdArgs, chart.Version)
+	}
+
+	if !chart.Kustomize.Empty() {
+		postRenderExecutable, err := SetupKustomize(dir, configFile, chart)
+		if err != nil {
+			return "", err
+		}
+		cmdArgs = append(cmdArgs, "--post-renderer")
+		cmdArgs = append(cmdArgs, postRenderExecutable)
+	}
+
+	cmdArgs = append(cmdArgs, args...)
+	cmdArgs = append(cmdArgs, chart.HelmArgs("template")...)
+
+	res, err = RunHelmCommand(cmdArgs...)
+	if err != nil {
+		return "", fmt.Errorf("running helm template for release %s: %s: %w", chart.Release, res.Stderr, err)
+	}
+
+	return strings.TrimSpace(res.Stdout), nil
+}
+
+// assignRenderedSources assigns the chart templates to post-rendered resources that lost their `# Source:`
+// comment, by rendering the chart once more without the kustomize post-renderer
+func assignRenderedSources(chart config.ChartConfig, configFile string, resources []Resource, args ...string) ([]Resource, error) {
+	var missing bool
+	for _, r := range resources {
+		missing = missing || len(r.Source) == 0
+	}
+
+	if !missing {
+		return resources, nil
+	}
+
+	chart.Kustomize = config.BinnacleKustomization{}
+
+	manifest, err := renderChart(chart, configFile, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := ParseManifest(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("reading rendered manifest for release %s: %w", chart.Release, err)
+	}
+
+	return assignSources(resources, rendered), nil
+}
+
 func templateCmdPostRun() {
 	log.Debug("Execution of the `template` command has completed.")
 }
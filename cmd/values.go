@@ -0,0 +1,168 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/Traackr/binnacle/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var valuesExplain bool
+var valuesRelease string
+
+// valuesCmd represents the values command
+var valuesCmd = &cobra.Command{
+	Use:   "values",
+	Short: "Displays the effective values that are passed to `helm` for a release within the given Binnacle configuration",
+	Long:  ``,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		valuesCmdPreRun()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return valuesCmdRun(args...)
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		valuesCmdPostRun()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(valuesCmd)
+
+	valuesCmd.Flags().BoolVar(&valuesExplain, "explain", false, "Annotate each value with the source that set it.")
+	valuesCmd.Flags().StringVar(&valuesRelease, "release", "", "The release to display the values of, as namespace/release. (required)")
+	valuesCmd.MarkFlagRequired("release")
+
+	addValueOverrideFlags(valuesCmd)
+}
+
+func valuesCmdPreRun() {
+	log.Debug("Executing `values` command.")
+}
+
+func valuesCmdRun(args ...string) error {
+	// Load our configuration
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	chart, err := c.FindChart(valuesRelease)
+	if err != nil {
+		return err
+	}
+
+	overrides, err := parseValueOverrides(c)
+	if err != nil {
+		return err
+	}
+
+	// Only the release is prepared, so that the chart defaults can be read
+	c.Charts = []config.ChartConfig{*chart}
+
+	// Sync repositories
+	if _, err := syncRepositories(c); err != nil {
+		return err
+	}
+
+	// Resolve chart versions
+	if err := resolveChartVersions(c, false); err != nil {
+		return err
+	}
+
+	// Fetch charts from other sources
+	if err := fetchCharts(c); err != nil {
+		return err
+	}
+
+	layers, err := chartValuesLayers(c, c.Charts[0], overrides, args...)
+	if err != nil {
+		return err
+	}
+
+	values, sources := config.MergeValues(layers...)
+
+	if valuesExplain {
+		y, err := config.ExplainValues(values, sources)
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(y)
+		return nil
+	}
+
+	y, err := yaml.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("marshalling chart values: %w", err)
+	}
+
+	fmt.Print(string(y))
+
+	return nil
+}
+
+func valuesCmdPostRun() {
+	log.Debug("Execution of the `values` command has completed.")
+}
+
+// chartValuesLayers returns the layers of valuesLayers, preceded by the default values of the chart.  Charts that
+// are absent are not rendered, so they have no defaults.
+func chartValuesLayers(c *config.BinnacleConfig, chart config.ChartConfig, overrides []config.ValueOverride, args ...string) ([]config.ValuesLayer, error) {
+	var layers []config.ValuesLayer
+
+	if chart.State == config.StatePresent {
+		defaults, err := getChartDefaultValues(chart, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		layers = append(layers, config.ValuesLayer{Source: chartDefaultsSource(chart), Values: defaults})
+	}
+
+	return append(layers, valuesLayers(c, chart, overrides)...), nil
+}
+
+// chartDefaultsSource returns the source of the default values of the chart, as shown by --explain
+func chartDefaultsSource(chart config.ChartConfig) string {
+	if len(chart.Version) > 0 {
+		return fmt.Sprintf("%s %s defaults", chart.ChartURL(), chart.Version)
+	}
+	return chart.ChartURL() + " defaults"
+}
+
+// valuesLayers returns the layers that make up the values of a chart, from lowest to highest precedence
+func valuesLayers(c *config.BinnacleConfig, chart config.ChartConfig, overrides []config.ValueOverride) []config.ValuesLayer {
+	layers := []config.ValuesLayer{
+		{Source: c.ConfigFile, Values: chart.Values},
+	}
+
+	for _, o := range overrides {
+		if o.Matches(chart) {
+			layers = append(layers, config.ValuesLayer{Source: o.Source, Values: o.Values()})
+		}
+	}
+
+	return layers
+}
@@ -0,0 +1,63 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/Traackr/binnacle/config"
+)
+
+func TestChartValuesLayers_ChartDefaults(t *testing.T) {
+	useFakeHelm(t, `printf 'imageTag: "3.9.0"\npersistence:\n  enabled: true\n'`)
+
+	c := &config.BinnacleConfig{ConfigFile: "binnacle.yml"}
+	chart := config.ChartConfig{
+		Name:      "concourse",
+		Namespace: "apps",
+		Release:   "apps-concourse",
+		Repo:      "stable",
+		State:     config.StatePresent,
+		Values:    map[string]any{"imageTag": "3.10.0"},
+		Version:   "1.3.1",
+	}
+
+	layers, err := chartValuesLayers(c, chart, nil)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	values, sources := config.MergeValues(layers...)
+
+	if values["imageTag"] != "3.10.0" || sources["imageTag"] != "binnacle.yml" {
+		t.Errorf("want imageTag from the config file, got %v from %s", values["imageTag"], sources["imageTag"])
+	}
+
+	if want := "stable/concourse 1.3.1 defaults"; sources["persistence.enabled"] != want {
+		t.Errorf("want persistence.enabled from %s, got %s", want, sources["persistence.enabled"])
+	}
+
+	chart.State = "absent"
+	layers, err = chartValuesLayers(c, chart, nil)
+	if err != nil || len(layers) != 1 {
+		t.Errorf("want only the config file layer for an absent chart, got %v, %v", layers, err)
+	}
+}
@@ -24,23 +24,48 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/Masterminds/semver/v3"
 	"gopkg.in/yaml.v3"
 )
 
 // ChartConfig definition
 type ChartConfig struct {
-	Kustomize BinnacleKustomization `mapstructure:"kustomize"`
-	Name      string                `mapstructure:"name"`
-	Namespace string                `mapstructure:"namespace"`
-	Release   string                `mapstructure:"release"`
-	Repo      string                `mapstructure:"repo"`
-	State     string                `mapstructure:"state"`
-	URL       string                `mapstructure:"url"`
-	Values    map[string]any        `mapstructure:"values"`
-	Version   string                `mapstructure:"version"`
+	// DiffIgnore are the rules that strip fields or resources from the diff of the release
+	DiffIgnore []DiffIgnoreRule `mapstructure:"diffIgnore"`
+	// ExtraArgs are extra helm arguments, keyed by the helm subcommand they are given to
+	ExtraArgs map[string][]string `mapstructure:"extraArgs"`
+	// FreeformValues are the value paths that lint --values accepts any keys and types under
+	FreeformValues []string `mapstructure:"freeformValues"`
+	// Git is the git repository the chart is checked out from
+	Git GitSource `mapstructure:"git"`
+	// Kustomize are the resources and patches applied to the rendered chart
+	Kustomize  BinnacleKustomization `mapstructure:"kustomize"`
+	LocalChart string                `mapstructure:"-"`
+	// Name is the name of the chart, an oci:// reference, or the path to a local chart
+	Name string `mapstructure:"name"`
+	// Namespace is the namespace the release is installed into
+	Namespace string `mapstructure:"namespace"`
+	// Release is the name of the release
+	Release string `mapstructure:"release"`
+	// Repo is the repository the chart is installed from
+	Repo string `mapstructure:"repo"`
+	// SHA256 is the digest the packaged chart downloaded from the url is verified against
+	SHA256 string `mapstructure:"sha256"`
+	// State determines if the release is installed or uninstalled
+	State string `mapstructure:"state"`
+	// URL is the http or https url of a packaged chart
+	URL string `mapstructure:"url"`
+	// Values are passed to helm to configure the chart
+	Values map[string]any `mapstructure:"values"`
+	// Version is the version of the chart, or a semver constraint.  The latest version is used if it is omitted.
+	Version string `mapstructure:"version"`
 }
 
+// ExtraArgsCommands are the helm subcommands that extra arguments can be given for
+var ExtraArgsCommands = []string{"diff", "status", "template", "uninstall", "upgrade"}
+
 // Adapted from https://github.com/kubernetes-sigs/kustomize/blob/master/api/types/kustomization.go
 type BinnacleKustomization struct {
 	// https://kubectl.docs.kubernetes.io/references/kustomize/kustomization/resource/
@@ -51,9 +76,13 @@ type BinnacleKustomization struct {
 }
 
 type Patch struct {
-	Path    string          `mapstructure:"path,omitempty" yaml:"path,omitempty"`
-	Patch   string          `mapstructure:"patch,omitempty" yaml:"patch,omitempty"`
-	Target  *Selector       `mapstructure:"target,omitempty" yaml:"target,omitempty"`
+	// Path is the patch file, relative to the config file
+	Path string `mapstructure:"path,omitempty" yaml:"path,omitempty"`
+	// Patch is an inline strategic merge or JSON 6902 patch
+	Patch string `mapstructure:"patch,omitempty" yaml:"patch,omitempty"`
+	// Target selects the resources the patch is applied to
+	Target *Selector `mapstructure:"target,omitempty" yaml:"target,omitempty"`
+	// Options are the patch options, such as allowNameChange and allowKindChange
 	Options map[string]bool `mapstructure:"options,omitempty" yaml:"options,omitempty"`
 }
 
@@ -87,7 +116,22 @@ func (k BinnacleKustomization) Empty() bool {
 // 3. By path to an unpacked chart directory: helm install mynginx ./nginx
 // 4. By absolute URL: helm install mynginx https://example.com/charts/nginx-1.2.3.tgz
 //
+// Charts downloaded from a url are given by their url.  Charts stored in an OCI registry are given with an
+// oci:// name, or an oci:// repo that the name is appended to.
 func (c ChartConfig) ChartURL() string {
+	// Charts fetched from another source, such as a git repository, are given to helm by their local path
+	if len(c.LocalChart) > 0 {
+		return c.LocalChart
+	}
+
+	if !c.Git.Empty() {
+		return c.Git.String()
+	}
+
+	if len(c.URL) > 0 {
+		return c.URL
+	}
+
 	// If a repository is given return the c
 	if len(c.Repo) > 0 {
 		return c.Repo + "/" + c.Name
@@ -95,6 +139,38 @@ func (c ChartConfig) ChartURL() string {
 	return c.Name
 }
 
+// IsLocal returns if the chart is a local chart directory or packaged chart, given by its path
+func (c ChartConfig) IsLocal() bool {
+	if len(c.Repo) > 0 || !c.Git.Empty() || len(c.URL) > 0 || strings.Contains(c.Name, "://") {
+		return false
+	}
+
+	return strings.HasPrefix(c.Name, "./") || strings.HasPrefix(c.Name, "../") || filepath.IsAbs(c.Name) ||
+		strings.HasSuffix(c.Name, ".tgz")
+}
+
+// IsOCI returns if the chart is stored in an OCI registry, rather than a chart repository
+func (c ChartConfig) IsOCI() bool {
+	return strings.HasPrefix(c.ChartURL(), OCIScheme)
+}
+
+// IsVersionRange returns if the version of the chart is a semver constraint, such as ~1.3 or >=2.0 <3, rather
+// than an exact version
+func (c ChartConfig) IsVersionRange() bool {
+	if len(c.Version) == 0 {
+		return false
+	}
+
+	_, err := semver.StrictNewVersion(strings.TrimPrefix(c.Version, "v"))
+
+	return err != nil
+}
+
+// HelmArgs returns the extra arguments of the chart for the given helm subcommand
+func (c ChartConfig) HelmArgs(subcommand string) []string {
+	return c.ExtraArgs[subcommand]
+}
+
 // WriteValueFile writes the given file containing the Chart's Values
 func (c ChartConfig) WriteValueFile(dir string) (string, error) {
 	// Marshall the values into a string
@@ -21,6 +21,7 @@
 package config
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -49,3 +50,197 @@ func TestChartURL_WithoutRepo(t *testing.T) {
 		t.Errorf("want chart URL %s, but got %s", want, got)
 	}
 }
+
+func TestChartURL_URL(t *testing.T) {
+	viper.SetConfigFile("../testdata/url.yml")
+	viper.ReadInConfig()
+	c, err := LoadAndValidateFromViper()
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	got := c.Charts[0].ChartURL()
+	want := "https://github.com/pantsel/konga/blob/master/charts/konga/konga-1.0.0.tgz?raw=true"
+	if got != want {
+		t.Errorf("want chart URL %s, but got %s", want, got)
+	}
+
+	if c.Charts[0].SHA256 != "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef" {
+		t.Errorf("want the sha256 digest to be loaded, got %q", c.Charts[0].SHA256)
+	}
+}
+
+func TestHelmArgs(t *testing.T) {
+	viper.SetConfigFile("../testdata/extra-args.yml")
+	viper.ReadInConfig()
+	c, err := LoadAndValidateFromViper()
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	got := c.Charts[0].HelmArgs("upgrade")
+	want := []string{"--atomic", "--timeout=10m"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want upgrade args %v, got %v", want, got)
+	}
+
+	if got := c.Charts[0].HelmArgs("status"); len(got) != 0 {
+		t.Errorf("want no status args, got %v", got)
+	}
+}
+
+func TestLoadAndValidateFromViper_InvalidExtraArgs(t *testing.T) {
+	viper.SetConfigFile("../testdata/invalid-extra-args.yml")
+	viper.ReadInConfig()
+
+	_, err := LoadAndValidateFromViper()
+	if err == nil {
+		t.Errorf("want an error for an unsupported extraArgs subcommand, but was nil")
+	}
+}
+
+func TestChartURL_OCI(t *testing.T) {
+	viper.SetConfigFile("../testdata/oci.yml")
+	viper.ReadInConfig()
+	c, err := LoadAndValidateFromViper()
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := []string{"oci://registry.example.com/charts/api", "oci://registry.example.com/charts/worker"}
+	for idx, chart := range c.Charts {
+		if got := chart.ChartURL(); got != want[idx] {
+			t.Errorf("want chart URL %s, but got %s", want[idx], got)
+		}
+
+		if !chart.IsOCI() {
+			t.Errorf("want chart %s to be an OCI chart", chart.Release)
+		}
+	}
+}
+
+func TestIsOCI_WithRepo(t *testing.T) {
+	chart := ChartConfig{Name: "concourse", Repo: "stable"}
+
+	if chart.IsOCI() {
+		t.Errorf("want chart %s to not be an OCI chart", chart.ChartURL())
+	}
+}
+
+func TestChartURL_Git(t *testing.T) {
+	viper.SetConfigFile("../testdata/git.yml")
+	viper.ReadInConfig()
+	c, err := LoadAndValidateFromViper()
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	chart := c.Charts[0]
+
+	want := "https://github.com/example/api.git//deploy/chart@v1.4.0"
+	if got := chart.ChartURL(); got != want {
+		t.Errorf("want chart URL %s, but got %s", want, got)
+	}
+
+	chart.LocalChart = "/tmp/api/deploy/chart"
+	if got := chart.ChartURL(); got != chart.LocalChart {
+		t.Errorf("want chart URL %s once fetched, but got %s", chart.LocalChart, got)
+	}
+}
+
+func TestLoadAndValidateFromViper_InvalidGit(t *testing.T) {
+	viper.SetConfigFile("../testdata/invalid-git.yml")
+	viper.ReadInConfig()
+
+	_, err := LoadAndValidateFromViper()
+	if err == nil {
+		t.Errorf("want an error for a git source without a ref, but was nil")
+	}
+}
+
+func TestIsLocal(t *testing.T) {
+	tests := []struct {
+		chart ChartConfig
+		want  bool
+	}{
+		{ChartConfig{Name: "./charts/api"}, true},
+		{ChartConfig{Name: "../charts/api"}, true},
+		{ChartConfig{Name: "/srv/charts/api"}, true},
+		{ChartConfig{Name: "api-1.0.0.tgz"}, true},
+		{ChartConfig{Name: "concourse", Repo: "stable"}, false},
+		{ChartConfig{Name: "concourse"}, false},
+		{ChartConfig{Name: "https://example.com/charts/api-1.0.0.tgz"}, false},
+		{ChartConfig{Name: "oci://registry.example.com/charts/api"}, false},
+	}
+
+	for _, test := range tests {
+		if got := test.chart.IsLocal(); got != test.want {
+			t.Errorf("want IsLocal %v for %s, got %v", test.want, test.chart.Name, got)
+		}
+	}
+}
+
+func TestLoadAndValidateFromViper_InvalidURL(t *testing.T) {
+	viper.SetConfigFile("../testdata/invalid-url.yml")
+	viper.ReadInConfig()
+
+	_, err := LoadAndValidateFromViper()
+	if err == nil {
+		t.Errorf("want an error for a url combined with a repo, but was nil")
+	}
+}
+
+func TestValidateChartURL_Digest(t *testing.T) {
+	tests := []struct {
+		chart ChartConfig
+		valid bool
+	}{
+		{ChartConfig{URL: "https://example.com/api-1.0.0.tgz"}, true},
+		{ChartConfig{URL: "https://example.com/api-1.0.0.tgz", SHA256: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}, true},
+		{ChartConfig{URL: "https://example.com/api-1.0.0.tgz", SHA256: "0123"}, false},
+		{ChartConfig{URL: "ftp://example.com/api-1.0.0.tgz"}, false},
+		{ChartConfig{SHA256: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}, false},
+	}
+
+	for _, test := range tests {
+		err := validateChartURL(test.chart)
+		if test.valid && err != nil {
+			t.Errorf("want no error for %s %s, got %v", test.chart.URL, test.chart.SHA256, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("want an error for %s %s, but was nil", test.chart.URL, test.chart.SHA256)
+		}
+	}
+}
+
+func TestIsVersionRange(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"", false},
+		{"1.3.1", false},
+		{"v1.3.1", false},
+		{"1.3.1-rc.1", false},
+		{"~1.3", true},
+		{">=2.0 <3", true},
+		{"1.3", true},
+	}
+
+	for _, test := range tests {
+		chart := ChartConfig{Version: test.version}
+		if got := chart.IsVersionRange(); got != test.want {
+			t.Errorf("want IsVersionRange %v for %q, got %v", test.want, test.version, got)
+		}
+	}
+}
+
+func TestLoadAndValidateFromViper_InvalidVersion(t *testing.T) {
+	viper.SetConfigFile("../testdata/invalid-version.yml")
+	viper.ReadInConfig()
+
+	_, err := LoadAndValidateFromViper()
+	if err == nil {
+		t.Errorf("want an error for a version that is not a semver constraint, but was nil")
+	}
+}
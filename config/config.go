@@ -21,33 +21,88 @@
 package config
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"strings"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/spf13/viper"
 )
 
+// APIVersion is the current version of the configuration file format
+const APIVersion = "binnacle/v1"
+
 // StatePresent represents the present state
 const StatePresent = "present"
 
+// StateAbsent represents the absent state
+const StateAbsent = "absent"
+
+// States are the states charts and repositories can be set to
+var States = []string{StateAbsent, StatePresent}
+
 // BinnacleConfig definition
 type BinnacleConfig struct {
-	Charts       []ChartConfig `mapstructure:"charts"`
-	ConfigFile   string
-	Context      string             `mapstructure:"kube-context"`
-	LogLevel     string             `mapstructure:"loglevel"`
-	Release      string             `mapstructure:"release"`
+	// APIVersion is the version of the configuration file format
+	APIVersion string `mapstructure:"apiVersion"`
+	// Charts are the releases managed by binnacle
+	Charts     []ChartConfig `mapstructure:"charts"`
+	ConfigFile string
+	// Context is the kubectl context that releases are managed in
+	Context string `mapstructure:"kube-context"`
+	// DiffIgnore are the rules that strip fields or resources from the diffs of all releases
+	DiffIgnore []DiffIgnoreRule `mapstructure:"diffIgnore"`
+	// IsolateRepositories uses a helm repository configuration and cache dedicated to the config file
+	IsolateRepositories bool `mapstructure:"isolateRepositories"`
+	// LogLevel is the level of logging, one of debug, info, warn, error, fatal or panic
+	LogLevel string `mapstructure:"loglevel"`
+	// Registries are the OCI registries that are logged in to
+	Registries []RegistryConfig `mapstructure:"registries"`
+	Release    string           `mapstructure:"release"`
+	// Repositories are the chart repositories that are added, updated or removed
 	Repositories []RepositoryConfig `mapstructure:"repositories"`
+	// SensitiveKinds are the resource kinds, besides Secrets, whose data is redacted from template and diff output
+	SensitiveKinds []string `mapstructure:"sensitiveKinds"`
 }
 
 // LoadAndValidateFromViper creates a BinnacleConfig object from Viper
 func LoadAndValidateFromViper() (*BinnacleConfig, error) {
+	return loadAndValidate(viper.GetViper())
+}
+
+// LoadAndValidate creates a BinnacleConfig object from the YAML configuration, as if it was read from the given
+// config file
+func LoadAndValidate(configFile string, data []byte) (*BinnacleConfig, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	v.SetConfigFile(configFile)
+
+	return loadAndValidate(v)
+}
+
+func loadAndValidate(v *viper.Viper) (*BinnacleConfig, error) {
 	var config BinnacleConfig
 
-	if err := viper.UnmarshalExact(&config); err != nil {
+	if err := v.UnmarshalExact(&config); err != nil {
 		return nil, fmt.Errorf("reading config file: %w", err)
 	}
 
-	config.ConfigFile = viper.ConfigFileUsed()
+	config.ConfigFile = v.ConfigFileUsed()
+
+	// Config files without an apiVersion predate it, and are read as the current version
+	if len(config.APIVersion) == 0 {
+		config.APIVersion = APIVersion
+	}
+
+	if config.APIVersion != APIVersion {
+		return nil, fmt.Errorf("validating apiVersion: unsupported apiVersion %q, expected %s", config.APIVersion, APIVersion)
+	}
 
 	// Set general defaults
 	if len(config.Context) == 0 {
@@ -85,6 +140,36 @@ func LoadAndValidateFromViper() (*BinnacleConfig, error) {
 	return &config, nil
 }
 
+// FindChart returns the chart for the given release, referenced as namespace/release.  The namespace can
+// be omitted if the release name is unique.
+func (c *BinnacleConfig) FindChart(ref string) (*ChartConfig, error) {
+	var found *ChartConfig
+
+	namespace, release, hasNamespace := strings.Cut(ref, "/")
+	if !hasNamespace {
+		release = ref
+	}
+
+	for idx := range c.Charts {
+		chart := &c.Charts[idx]
+
+		if chart.Release != release || (hasNamespace && chart.Namespace != namespace) {
+			continue
+		}
+
+		if found != nil {
+			return nil, fmt.Errorf("finding release %s: release is ambiguous, use namespace/release", ref)
+		}
+		found = chart
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("finding release %s: release not found", ref)
+	}
+
+	return found, nil
+}
+
 func cleanupInterfaceArray(in []interface{}) []interface{} {
 	res := make([]interface{}, len(in))
 	for i, v := range in {
@@ -117,5 +202,135 @@ func cleanupMapValue(v interface{}) interface{} {
 }
 
 func validateConfig(c *BinnacleConfig) error {
+	if err := validateDiffIgnoreRules(c.DiffIgnore); err != nil {
+		return err
+	}
+
+	for _, chart := range c.Charts {
+		// The namespace is optional, helm uses the namespace of the current kube-context without one
+		if len(chart.Release) == 0 {
+			return fmt.Errorf("validating chart %s/%s: release is required", chart.Namespace, chart.Release)
+		}
+
+		// Charts from a git source are found by their path within the repository
+		if len(chart.Name) == 0 && chart.Git.Empty() {
+			return fmt.Errorf("validating chart %s/%s: name is required", chart.Namespace, chart.Release)
+		}
+
+		if err := validateState(chart.State); err != nil {
+			return fmt.Errorf("validating chart %s/%s: %w", chart.Namespace, chart.Release, err)
+		}
+
+		if err := validateDiffIgnoreRules(chart.DiffIgnore); err != nil {
+			return fmt.Errorf("validating chart %s/%s: %w", chart.Namespace, chart.Release, err)
+		}
+
+		if err := validateExtraArgs(chart.ExtraArgs); err != nil {
+			return fmt.Errorf("validating chart %s/%s: %w", chart.Namespace, chart.Release, err)
+		}
+
+		if !chart.Git.Empty() {
+			if err := chart.Git.validate(); err != nil {
+				return fmt.Errorf("validating chart %s/%s: %w", chart.Namespace, chart.Release, err)
+			}
+
+			if len(chart.Repo) > 0 {
+				return fmt.Errorf("validating chart %s/%s: repo can not be combined with a git source", chart.Namespace, chart.Release)
+			}
+		}
+
+		if err := validateChartURL(chart); err != nil {
+			return fmt.Errorf("validating chart %s/%s: %w", chart.Namespace, chart.Release, err)
+		}
+
+		if chart.IsVersionRange() {
+			if _, err := semver.NewConstraint(chart.Version); err != nil {
+				return fmt.Errorf("validating chart %s/%s: version %q is neither a version nor a semver constraint: %w", chart.Namespace, chart.Release, chart.Version, err)
+			}
+		}
+	}
+
+	for _, repo := range c.Repositories {
+		if err := validateState(repo.State); err != nil {
+			return fmt.Errorf("validating repository %s: %w", repo.Name, err)
+		}
+
+		if err := repo.validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, registry := range c.Registries {
+		if err := registry.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateState checks that the state is one of the supported States
+func validateState(state string) error {
+	for _, s := range States {
+		if state == s {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("validating state: unsupported state %q, expected one of %s", state, strings.Join(States, ", "))
+}
+
+func validateChartURL(chart ChartConfig) error {
+	if len(chart.URL) == 0 {
+		if len(chart.SHA256) > 0 {
+			return fmt.Errorf("validating sha256: sha256 requires a url")
+		}
+		return nil
+	}
+
+	if len(chart.Repo) > 0 || !chart.Git.Empty() {
+		return fmt.Errorf("validating url: url can not be combined with a repo or git source")
+	}
+
+	if !strings.HasPrefix(chart.URL, "https://") && !strings.HasPrefix(chart.URL, "http://") {
+		return fmt.Errorf("validating url: %s is not an http or https url", chart.URL)
+	}
+
+	if len(chart.SHA256) > 0 {
+		if _, err := hex.DecodeString(chart.SHA256); err != nil || len(chart.SHA256) != sha256.Size*2 {
+			return fmt.Errorf("validating sha256: %s is not a sha256 hex digest", chart.SHA256)
+		}
+	}
+
+	return nil
+}
+
+func validateExtraArgs(extraArgs map[string][]string) error {
+	for subcommand := range extraArgs {
+		valid := false
+		for _, c := range ExtraArgsCommands {
+			if subcommand == c {
+				valid = true
+				break
+			}
+		}
+
+		if !valid {
+			return fmt.Errorf("validating extraArgs: unsupported helm subcommand %q, expected one of %s", subcommand, strings.Join(ExtraArgsCommands, ", "))
+		}
+	}
+
+	return nil
+}
+
+func validateDiffIgnoreRules(rules []DiffIgnoreRule) error {
+	for _, rule := range rules {
+		for _, p := range rule.Paths {
+			if _, err := ParseFieldPath(p); err != nil {
+				return fmt.Errorf("validating diffIgnore rule: %w", err)
+			}
+		}
+	}
+
 	return nil
 }
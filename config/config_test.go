@@ -72,3 +72,65 @@ func TestLoadAndValidateFromViper_DefaultRepoState(t *testing.T) {
 		t.Errorf("want state to be %s, but got %s", want, got)
 	}
 }
+
+func TestFindChart(t *testing.T) {
+	viper.SetConfigFile("../testdata/demo.yml")
+	viper.ReadInConfig()
+	c, _ := LoadAndValidateFromViper()
+
+	for _, ref := range []string{"apps/apps-concourse", "apps-concourse"} {
+		chart, err := c.FindChart(ref)
+		if err != nil {
+			t.Errorf("want no error for %s, got %v", ref, err)
+			continue
+		}
+		if chart != &c.Charts[0] {
+			t.Errorf("want %s to find the first chart", ref)
+		}
+	}
+
+	for _, ref := range []string{"other/apps-concourse", "missing"} {
+		if _, err := c.FindChart(ref); err == nil {
+			t.Errorf("want an error for %s, but was nil", ref)
+		}
+	}
+}
+
+func TestLoadAndValidate_APIVersion(t *testing.T) {
+	c, err := LoadAndValidate("binnacle.yml", []byte("charts: []\n"))
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if c.APIVersion != APIVersion {
+		t.Errorf("want apiVersion %s by default, got %s", APIVersion, c.APIVersion)
+	}
+
+	if _, err := LoadAndValidate("binnacle.yml", []byte("apiVersion: binnacle/v2\n")); err == nil {
+		t.Errorf("want an error for an unsupported apiVersion, but was nil")
+	}
+}
+
+func TestValidateConfig_RequiredFieldsAndState(t *testing.T) {
+	tests := map[string]BinnacleConfig{
+		"unknown chart state": {Charts: []ChartConfig{{Name: "api", Namespace: "apps", Release: "api", State: "absnet"}}},
+		"missing name":        {Charts: []ChartConfig{{Namespace: "apps", Release: "api", State: StatePresent}}},
+		"missing release":     {Charts: []ChartConfig{{Name: "api", Namespace: "apps", State: StatePresent}}},
+		"unknown repo state":  {Repositories: []RepositoryConfig{{Name: "stable", URL: "https://charts.example.com", State: "gone"}}},
+	}
+
+	for name, c := range tests {
+		if err := validateConfig(&c); err == nil {
+			t.Errorf("want an error for %s, but was nil", name)
+		}
+	}
+
+	valid := []BinnacleConfig{
+		{Charts: []ChartConfig{{Name: "api", Namespace: "apps", Release: "api", State: StateAbsent}}},
+		{Charts: []ChartConfig{{Name: "api", Release: "api", State: StatePresent}}},
+	}
+	for _, c := range valid {
+		if err := validateConfig(&c); err != nil {
+			t.Errorf("want no error for %v, got %v", c.Charts, err)
+		}
+	}
+}
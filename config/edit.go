@@ -0,0 +1,552 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	yamlv2 "gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a YAML configuration file that is edited in place.  Edits splice the source of the file, so the
+// comments, ordering and formatting around the edited fields are kept.
+type Document struct {
+	data    []byte
+	root    *yaml.Node
+	parents map[*yaml.Node]*yaml.Node
+}
+
+// ReadDocument reads the YAML configuration file at the given path for editing
+func ReadDocument(path string) (*Document, error) {
+	if ext := filepath.Ext(path); ext != ".yml" && ext != ".yaml" {
+		return nil, fmt.Errorf("editing config file %s: only YAML config files can be edited", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	return ParseDocument(data)
+}
+
+// ParseDocument parses the YAML configuration for editing
+func ParseDocument(data []byte) (*Document, error) {
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing config file: %w", err)
+	}
+
+	d := &Document{data: data, parents: make(map[*yaml.Node]*yaml.Node)}
+
+	if len(doc.Content) == 0 {
+		return d, nil
+	}
+
+	d.root = doc.Content[0]
+	if d.root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parsing config file: the top level is not a mapping")
+	}
+	d.recordParents(&doc)
+
+	return d, nil
+}
+
+// Bytes returns the edited configuration
+func (d *Document) Bytes() []byte {
+	return d.data
+}
+
+// Chart returns the mapping of the chart of the given release, referenced as namespace/release.  The namespace
+// can be omitted if the release name is unique.
+func (d *Document) Chart(ref string) (*yaml.Node, error) {
+	var found *yaml.Node
+
+	namespace, release, hasNamespace := strings.Cut(ref, "/")
+	if !hasNamespace {
+		release = ref
+	}
+
+	for _, chart := range d.items("charts") {
+		if scalarValue(chart, "release") != release || (hasNamespace && scalarValue(chart, "namespace") != namespace) {
+			continue
+		}
+
+		if found != nil {
+			return nil, fmt.Errorf("finding release %s: release is ambiguous, use namespace/release", ref)
+		}
+		found = chart
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("finding release %s: release not found", ref)
+	}
+
+	return found, nil
+}
+
+// ParseAssignment parses a field assignment given as path=value, where the path is separated by dots.  Dots that
+// are part of a key can be escaped with a backslash.
+func ParseAssignment(s string) ([]string, string, error) {
+	key, value, found := strings.Cut(s, "=")
+	if !found || len(key) == 0 {
+		return nil, "", fmt.Errorf("parsing %q: expected path=value", s)
+	}
+
+	path := splitValuePath(key)
+	for _, segment := range path {
+		if len(segment) == 0 {
+			return nil, "", fmt.Errorf("parsing %q: empty key in path", s)
+		}
+	}
+
+	return path, value, nil
+}
+
+// Repository returns the mapping of the repository with the given name
+func (d *Document) Repository(name string) (*yaml.Node, error) {
+	for _, repo := range d.items("repositories") {
+		if scalarValue(repo, "name") == name {
+			return repo, nil
+		}
+	}
+
+	return nil, fmt.Errorf("finding repository %s: repository not found", name)
+}
+
+// AppendItem appends a mapping with the given string entries to the top-level sequence with the given key.  The
+// sequence is created if it does not exist.
+func (d *Document) AppendItem(key string, entries [][2]string) error {
+	var keyNode, seq *yaml.Node
+
+	if d.root != nil {
+		keyNode, seq = mappingEntry(d.root, key)
+	}
+
+	switch {
+	case seq == nil:
+		offset := len(d.data)
+		return d.splice(offset, offset, formatKey(key)+":\n"+formatItem("  ", entries)+"\n")
+	case seq.Kind == yaml.ScalarNode && seq.Tag == "!!null" && len(seq.Value) == 0:
+		offset := d.afterKey(keyNode)
+		return d.splice(offset, offset, "\n"+formatItem("  ", entries))
+	case seq.Kind != yaml.SequenceNode || seq.Style&yaml.FlowStyle != 0 || len(seq.Content) == 0:
+		return fmt.Errorf("adding to %s: only block sequences can be edited", key)
+	}
+
+	// Indent the new item like the first item
+	first := seq.Content[0]
+	line := d.lines()[first.Line-1]
+	dash := bytes.LastIndexByte(line[:d.offset(first.Line, first.Column)-d.lineOffset(first.Line-1)], '-')
+	if dash < 0 {
+		return fmt.Errorf("adding to %s: only block sequences can be edited", key)
+	}
+
+	offset := d.lineOffset(d.regionEnd(seq))
+
+	return d.splice(offset, offset, formatItem(strings.Repeat(" ", dash), entries)+"\n")
+}
+
+// PrependEntry adds the top-level key with the string value, followed by a blank line, before the rest of the
+// configuration.  A leading document marker is kept first.
+func (d *Document) PrependEntry(key string, value string) error {
+	var offset int
+
+	if d.root != nil {
+		if existing, _ := mappingEntry(d.root, key); existing != nil {
+			return fmt.Errorf("adding %s: %s already exists", key, key)
+		}
+	}
+
+	if bytes.HasPrefix(d.data, []byte("---\n")) {
+		offset = len("---\n")
+	}
+
+	return d.splice(offset, offset, formatEntry("", []string{key}, value, true)+"\n\n")
+}
+
+// RemoveItem removes the item from its sequence, along with the comment lines right above it
+func (d *Document) RemoveItem(item *yaml.Node) error {
+	parent := d.parents[item]
+	if parent == nil || parent.Kind != yaml.SequenceNode || parent.Style&yaml.FlowStyle != 0 {
+		return fmt.Errorf("removing item: only items of block sequences can be removed")
+	}
+
+	lines := d.lines()
+
+	start := item.Line - 1
+	for start > 0 && isComment(lines[start-1]) {
+		start--
+	}
+
+	// Keep a single blank line between the items around the removed one
+	end := d.regionEnd(item)
+	if start > 0 && end < len(lines) && isBlank(lines[start-1]) && isBlank(lines[end]) {
+		end++
+	}
+
+	return d.splice(d.lineOffset(start), d.lineOffset(end), "")
+}
+
+// Set sets the field at the given path within the mapping to the value, which is written as a number, boolean
+// or null when it is one, and as a string otherwise.  Missing mappings along the path are created.  A scalar
+// that is replaced keeps its quoting style.
+func (d *Document) Set(mapping *yaml.Node, path []string, value string) error {
+	return d.set(mapping, path, value, false)
+}
+
+// SetString sets the field at the given path within the mapping to the value, which is always written as a
+// string
+func (d *Document) SetString(mapping *yaml.Node, path []string, value string) error {
+	return d.set(mapping, path, value, true)
+}
+
+func (d *Document) set(mapping *yaml.Node, path []string, value string, str bool) error {
+	if mapping.Kind != yaml.MappingNode || mapping.Style&yaml.FlowStyle != 0 {
+		return fmt.Errorf("setting %s: only block mappings can be edited", strings.Join(path, "."))
+	}
+
+	key, existing := mappingEntry(mapping, path[0])
+	if existing == nil {
+		return d.insertEntry(mapping, path, value, str)
+	}
+
+	// An empty value, such as `values:` without any content, is filled in after the key
+	if existing.Kind == yaml.ScalarNode && existing.Tag == "!!null" && len(existing.Value) == 0 {
+		offset := d.afterKey(key)
+		indent := strings.Repeat(" ", key.Column-1)
+
+		if len(path) == 1 {
+			return d.splice(offset, offset, " "+formatScalar(value, 0, str))
+		}
+		return d.splice(offset, offset, "\n"+formatEntry(indent+"  ", path[1:], value, str))
+	}
+
+	if len(path) > 1 {
+		if existing.Kind != yaml.MappingNode {
+			return fmt.Errorf("setting %s: %s is not a mapping", strings.Join(path, "."), path[0])
+		}
+		return d.set(existing, path[1:], value, str)
+	}
+
+	if existing.Kind != yaml.ScalarNode || existing.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return fmt.Errorf("setting %s: only single line values can be replaced", path[0])
+	}
+
+	start := d.offset(existing.Line, existing.Column)
+	end := start + scalarLength(d.data[start:], existing.Style)
+
+	return d.splice(start, end, formatScalar(value, existing.Style, str))
+}
+
+// items returns the items of the top-level sequence with the given key
+func (d *Document) items(key string) []*yaml.Node {
+	if d.root == nil {
+		return nil
+	}
+
+	_, seq := mappingEntry(d.root, key)
+	if seq == nil || seq.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	return seq.Content
+}
+
+// insertEntry adds the path to the end of the mapping
+func (d *Document) insertEntry(mapping *yaml.Node, path []string, value string, str bool) error {
+	if len(mapping.Content) == 0 {
+		return fmt.Errorf("setting %s: empty mappings can not be edited", strings.Join(path, "."))
+	}
+
+	indent := strings.Repeat(" ", mapping.Content[0].Column-1)
+	offset := d.lineOffset(d.regionEnd(mapping))
+
+	return d.splice(offset, offset, formatEntry(indent, path, value, str)+"\n")
+}
+
+// splice replaces the source between the offsets with the text, and parses the result again
+func (d *Document) splice(start int, end int, text string) error {
+	var data []byte
+
+	data = append(data, d.data[:start]...)
+	data = append(data, text...)
+	data = append(data, d.data[end:]...)
+
+	// A missing newline at the end of the file would join an appended line with the last line
+	if start == len(d.data) && len(d.data) > 0 && d.data[len(d.data)-1] != '\n' {
+		data = append(append(append([]byte{}, d.data...), '\n'), text...)
+	}
+
+	edited, err := ParseDocument(data)
+	if err != nil {
+		return fmt.Errorf("editing config file: %w", err)
+	}
+	*d = *edited
+
+	return nil
+}
+
+func (d *Document) recordParents(n *yaml.Node) {
+	for _, child := range n.Content {
+		d.parents[child] = n
+		d.recordParents(child)
+	}
+}
+
+// lines returns the source lines, including their line endings
+func (d *Document) lines() [][]byte {
+	return bytes.SplitAfter(d.data, []byte("\n"))
+}
+
+// lineOffset returns the offset of the start of the given 0-based line
+func (d *Document) lineOffset(line int) int {
+	offset := 0
+	for idx, l := range d.lines() {
+		if idx == line {
+			break
+		}
+		offset += len(l)
+	}
+
+	return offset
+}
+
+// offset returns the byte offset of the given 1-based line and column, as reported by yaml.v3 nodes
+func (d *Document) offset(line int, column int) int {
+	offset := d.lineOffset(line - 1)
+	for i := 1; i < column && offset < len(d.data); i++ {
+		_, size := utf8.DecodeRune(d.data[offset:])
+		offset += size
+	}
+
+	return offset
+}
+
+// afterKey returns the offset just after the colon that follows the key
+func (d *Document) afterKey(key *yaml.Node) int {
+	start := d.offset(key.Line, key.Column)
+
+	return start + bytes.IndexByte(d.data[start:], ':') + 1
+}
+
+// regionEnd returns the 0-based line after the last line of the node.  The region of a node ends where the
+// next node of its parents starts, without the blank and comment lines before it, which belong to the next
+// node.
+func (d *Document) regionEnd(n *yaml.Node) int {
+	lines := d.lines()
+
+	end := d.nextNodeLine(n)
+	for end > n.Line && isBlankOrComment(lines[end-1]) {
+		end--
+	}
+
+	return end
+}
+
+// nextNodeLine returns the 0-based line of the node that follows the given node, or the number of lines
+func (d *Document) nextNodeLine(n *yaml.Node) int {
+	parent := d.parents[n]
+	if parent == nil || parent.Kind == yaml.DocumentNode {
+		lines := d.lines()
+		if len(lines[len(lines)-1]) == 0 {
+			return len(lines) - 1
+		}
+		return len(lines)
+	}
+
+	for idx, child := range parent.Content {
+		if child != n || idx+1 >= len(parent.Content) {
+			continue
+		}
+
+		next := parent.Content[idx+1]
+		// The value of a mapping entry is part of the region of its key
+		if parent.Kind == yaml.MappingNode && idx%2 == 0 {
+			return d.nextNodeLine(next)
+		}
+		return next.Line - 1
+	}
+
+	return d.nextNodeLine(parent)
+}
+
+// mappingEntry returns the key and value nodes of the given key in the mapping
+func mappingEntry(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+
+	return nil, nil
+}
+
+// scalarValue returns the value of the scalar with the given key in the mapping
+func scalarValue(mapping *yaml.Node, key string) string {
+	_, value := mappingEntry(mapping, key)
+	if value == nil || value.Kind != yaml.ScalarNode {
+		return ""
+	}
+
+	return value.Value
+}
+
+// isComment returns if the line only holds a comment
+func isComment(line []byte) bool {
+	trimmed := bytes.TrimSpace(line)
+
+	return len(trimmed) > 0 && trimmed[0] == '#'
+}
+
+// isBlank returns if the line is empty or only holds whitespace
+func isBlank(line []byte) bool {
+	return len(bytes.TrimSpace(line)) == 0
+}
+
+// isBlankOrComment returns if the line holds no YAML content
+func isBlankOrComment(line []byte) bool {
+	trimmed := bytes.TrimSpace(line)
+
+	return len(trimmed) == 0 || trimmed[0] == '#'
+}
+
+// scalarLength returns the length of the single line scalar at the start of the source
+func scalarLength(src []byte, style yaml.Style) int {
+	switch {
+	case style&yaml.DoubleQuotedStyle != 0:
+		for i := 1; i < len(src); i++ {
+			if src[i] == '\\' {
+				i++
+				continue
+			}
+			if src[i] == '"' {
+				return i + 1
+			}
+		}
+	case style&yaml.SingleQuotedStyle != 0:
+		for i := 1; i < len(src); i++ {
+			if src[i] != '\'' {
+				continue
+			}
+			if i+1 < len(src) && src[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+
+	// Plain scalars end at the end of the line, or at a comment
+	end := bytes.IndexByte(src, '\n')
+	if end < 0 {
+		end = len(src)
+	}
+	if comment := bytes.Index(src[:end], []byte(" #")); comment >= 0 {
+		end = comment
+	}
+
+	return len(bytes.TrimRight(src[:end], " \t\r"))
+}
+
+// formatScalar returns the YAML source of the value.  Values are written as strings, quoted when needed, unless
+// str is not set and helm's --set would type them as integers, booleans or null, which keeps both key=value
+// syntaxes consistent.  The quoting style of a replaced value is kept.
+func formatScalar(value string, style yaml.Style, str bool) string {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Value: value, Tag: "!!str"}
+
+	if _, isString := typedValue(value).(string); !isString && !str {
+		node.Tag = ""
+	}
+
+	if style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		node.Tag = "!!str"
+		node.Style = style
+	}
+
+	out, err := yaml.Marshal(node)
+	if err != nil {
+		return value
+	}
+	src := strings.TrimSuffix(string(out), "\n")
+
+	// yaml.v3 writes strings such as on, 0755 or 1.20 plain, which the loader reads as booleans and numbers
+	if node.Tag == "!!str" && !loadsAsString(src, value) {
+		node.Style = yaml.DoubleQuotedStyle
+
+		if out, err = yaml.Marshal(node); err != nil {
+			return value
+		}
+		src = strings.TrimSuffix(string(out), "\n")
+	}
+
+	return src
+}
+
+// loadsAsString returns if the config loader reads the YAML source of a scalar as the given string.  Config files
+// are loaded by viper with yaml.v2, which follows YAML 1.1, so plain scalars such as on, yes, 010 and 1.20 are
+// booleans and numbers, while yaml.v3 reads them as strings.
+func loadsAsString(src string, value string) bool {
+	var decoded any
+
+	if err := yamlv2.Unmarshal([]byte(src), &decoded); err != nil {
+		return false
+	}
+
+	s, ok := decoded.(string)
+	return ok && s == value
+}
+
+// formatEntry returns the YAML source of the path set to the value, as nested block mappings
+func formatEntry(indent string, path []string, value string, str bool) string {
+	if len(path) == 1 {
+		return fmt.Sprintf("%s%s: %s", indent, formatKey(path[0]), formatScalar(value, 0, str))
+	}
+
+	return fmt.Sprintf("%s%s:\n%s", indent, formatKey(path[0]), formatEntry(indent+"  ", path[1:], value, str))
+}
+
+// formatItem returns the YAML source of a sequence item holding a mapping of the given string entries
+func formatItem(indent string, entries [][2]string) string {
+	var lines []string
+
+	for idx, entry := range entries {
+		prefix := indent + "  "
+		if idx == 0 {
+			prefix = indent + "- "
+		}
+		lines = append(lines, prefix+formatEntry("", []string{entry[0]}, entry[1], true))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatKey returns the YAML source of a mapping key, quoted when the loader would not read it as a string
+func formatKey(key string) string {
+	return formatScalar(key, 0, true)
+}
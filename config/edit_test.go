@@ -0,0 +1,237 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func readDemoDocument(t *testing.T) (*Document, string) {
+	t.Helper()
+
+	data, err := os.ReadFile("../testdata/demo.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := ParseDocument(data)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	return d, string(data)
+}
+
+func TestDocumentSet_ReplaceKeepsComments(t *testing.T) {
+	d, source := readDemoDocument(t)
+
+	chart, err := d.Chart("apps/apps-concourse")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if err := d.SetString(chart, []string{"version"}, "1.10"); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := strings.Replace(source, "version: 1.3.1", `version: "1.10"`, 1)
+	if got := string(d.Bytes()); got != want {
+		t.Errorf("want only the version to change, got:\n%s", got)
+	}
+}
+
+func TestDocumentSet_KeepsQuoteStyle(t *testing.T) {
+	d, source := readDemoDocument(t)
+
+	chart, _ := d.Chart("apps-concourse")
+	if err := d.Set(chart, []string{"values", "imageTag"}, "3.11.0"); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := strings.Replace(source, `imageTag: "3.10.0"`, `imageTag: "3.11.0"`, 1)
+	if got := string(d.Bytes()); got != want {
+		t.Errorf("want the quoted value to stay quoted, got:\n%s", got)
+	}
+}
+
+func TestDocumentSet_Insert(t *testing.T) {
+	d, source := readDemoDocument(t)
+
+	chart, _ := d.Chart("apps/apps-concourse")
+	if err := d.Set(chart, []string{"values", "ingress", "hosts", "primary"}, "ci.example.com"); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	chart, _ = d.Chart("apps/apps-concourse")
+	if err := d.Set(chart, []string{"kustomize", "resources"}, "null"); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := strings.Replace(source, `        enabled: true
+`, `        enabled: true
+        hosts:
+          primary: ci.example.com
+`, 1)
+	want = strings.Replace(want, `    version: 1.3.1
+`, `    version: 1.3.1
+    kustomize:
+      resources: null
+`, 1)
+	if got := string(d.Bytes()); got != want {
+		t.Errorf("want the new fields at the end of their mappings, got:\n%s", got)
+	}
+}
+
+func TestDocumentSet_EmptyValue(t *testing.T) {
+	d, err := ParseDocument([]byte("charts:\n  - release: api\n    values:\n    version: 1.0.0\n"))
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	chart, _ := d.Chart("api")
+	if err := d.Set(chart, []string{"values", "replicas"}, "3"); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := "charts:\n  - release: api\n    values:\n      replicas: 3\n    version: 1.0.0\n"
+	if got := string(d.Bytes()); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestDocumentChart_NotFound(t *testing.T) {
+	d, _ := readDemoDocument(t)
+
+	if _, err := d.Chart("apps/missing"); err == nil {
+		t.Errorf("want an error for a missing release, but was nil")
+	}
+}
+
+func TestFormatScalar(t *testing.T) {
+	tests := []struct {
+		value string
+		str   bool
+		want  string
+	}{
+		{"1.3.1", false, "1.3.1"},
+		{"1.10", false, `"1.10"`},
+		{"1.10", true, `"1.10"`},
+		{"true", false, "true"},
+		{"true", true, `"true"`},
+		{"3", false, "3"},
+		{"3", true, `"3"`},
+		{"null", false, "null"},
+		{"on", false, `"on"`},
+		{"yes", false, `"yes"`},
+		{"0755", false, `"0755"`},
+		{"1e3", false, `"1e3"`},
+		{"", false, `""`},
+		{"a: b", false, `'a: b'`},
+		{"concourse/concourse", false, "concourse/concourse"},
+	}
+
+	for _, test := range tests {
+		if got := formatScalar(test.value, 0, test.str); got != test.want {
+			t.Errorf("want %s for %q, got %s", test.want, test.value, got)
+		}
+	}
+}
+
+func TestDocumentAppendItem(t *testing.T) {
+	d, source := readDemoDocument(t)
+
+	if err := d.AppendItem("repositories", [][2]string{{"name", "internal"}, {"url", "https://charts.example.com"}}); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if err := d.AppendItem("charts", [][2]string{{"namespace", "apps"}, {"release", "api"}}); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := strings.Replace(source, `    version: 1.3.1
+`, `    version: 1.3.1
+  - namespace: apps
+    release: api
+`, 1)
+	want += "  - name: internal\n    url: https://charts.example.com\n"
+	if got := string(d.Bytes()); got != want {
+		t.Errorf("want the items appended to their sequences, got:\n%s", got)
+	}
+}
+
+func TestDocumentAppendItem_NewSequence(t *testing.T) {
+	d, err := ParseDocument([]byte("---\n# Charts\ncharts:\n  - release: api\n"))
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if err := d.AppendItem("repositories", [][2]string{{"name", "stable"}, {"url", "https://charts.example.com"}}); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := "---\n# Charts\ncharts:\n  - release: api\nrepositories:\n  - name: stable\n    url: https://charts.example.com\n"
+	if got := string(d.Bytes()); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestDocumentRemoveItem(t *testing.T) {
+	d, err := ParseDocument([]byte(`charts:
+  # The API
+  - release: api
+    version: 1.0.0
+
+  # The worker
+  - release: worker
+    version: 2.0.0
+    values:
+      replicas: 3
+
+  # The scheduler
+  - release: cron
+
+repositories: []
+`))
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	chart, _ := d.Chart("worker")
+	if err := d.RemoveItem(chart); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := `charts:
+  # The API
+  - release: api
+    version: 1.0.0
+
+  # The scheduler
+  - release: cron
+
+repositories: []
+`
+	if got := string(d.Bytes()); got != want {
+		t.Errorf("want the item and its comment removed, got:\n%s", got)
+	}
+}
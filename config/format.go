@@ -0,0 +1,184 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ChartKeyOrder is the canonical order of the keys of a chart.  Unknown keys follow, in their original order.
+var ChartKeyOrder = []string{
+	"name", "namespace", "release", "repo", "url", "sha256", "git", "state", "values", "freeformValues",
+	"kustomize", "diffIgnore", "extraArgs", "version",
+}
+
+// RepositoryKeyOrder is the canonical order of the keys of a repository
+var RepositoryKeyOrder = []string{
+	"name", "url", "username", "password", "caFile", "certFile", "keyFile", "insecureSkipTLSverify",
+	"passCredentials", "state",
+}
+
+// ambiguousString matches plain strings that other YAML parsers, or readers, take for numbers or booleans
+var ambiguousString = regexp.MustCompile(`^([-+]?[0-9][0-9._]*|0[xXoO][0-9a-fA-F_]+|(?i:y|n|yes|no|on|off|true|false))$`)
+
+// Format returns the YAML configuration in its canonical format.  Charts are sorted by namespace and release,
+// the keys of charts and repositories are put in their canonical order, and strings that look like numbers or
+// booleans are quoted.  The loaded configuration is never changed.  Comments are kept.
+func Format(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	var buf bytes.Buffer
+
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing config file: %w", err)
+	}
+
+	if len(doc.Content) == 0 {
+		return data, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parsing config file: the top level is not a mapping")
+	}
+
+	if _, charts := mappingEntry(root, "charts"); charts != nil && charts.Kind == yaml.SequenceNode {
+		sort.SliceStable(charts.Content, func(a, b int) bool {
+			return chartSortKey(charts.Content[a]) < chartSortKey(charts.Content[b])
+		})
+
+		for _, chart := range charts.Content {
+			orderKeys(chart, ChartKeyOrder)
+		}
+	}
+
+	if _, repos := mappingEntry(root, "repositories"); repos != nil && repos.Kind == yaml.SequenceNode {
+		for _, repo := range repos.Content {
+			orderKeys(repo, RepositoryKeyOrder)
+		}
+	}
+
+	quoteAmbiguousStrings(root)
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("formatting config file: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("formatting config file: %w", err)
+	}
+
+	formatted := separateTopLevelKeys(buf.Bytes())
+	if bytes.HasPrefix(data, []byte("---")) {
+		formatted = append([]byte("---\n"), formatted...)
+	}
+
+	return formatted, nil
+}
+
+// chartSortKey returns the namespace/release of the chart
+func chartSortKey(chart *yaml.Node) string {
+	if chart.Kind != yaml.MappingNode {
+		return ""
+	}
+
+	return scalarValue(chart, "namespace") + "/" + scalarValue(chart, "release")
+}
+
+// orderKeys sorts the entries of the mapping in the given key order
+func orderKeys(mapping *yaml.Node, order []string) {
+	if mapping.Kind != yaml.MappingNode {
+		return
+	}
+
+	rank := make(map[string]int)
+	for idx, key := range order {
+		rank[key] = idx
+	}
+
+	type entry struct {
+		key   *yaml.Node
+		value *yaml.Node
+	}
+
+	var entries []entry
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		entries = append(entries, entry{mapping.Content[i], mapping.Content[i+1]})
+	}
+
+	position := func(e entry) int {
+		if r, found := rank[e.key.Value]; found {
+			return r
+		}
+		return len(order)
+	}
+
+	sort.SliceStable(entries, func(a, b int) bool {
+		return position(entries[a]) < position(entries[b])
+	})
+
+	mapping.Content = mapping.Content[:0]
+	for _, e := range entries {
+		mapping.Content = append(mapping.Content, e.key, e.value)
+	}
+}
+
+// quoteAmbiguousStrings double quotes the plain string values that look like numbers or booleans
+func quoteAmbiguousStrings(n *yaml.Node) {
+	for idx, child := range n.Content {
+		// Keys are left as they are
+		if n.Kind == yaml.MappingNode && idx%2 == 0 {
+			continue
+		}
+
+		// Only strings are quoted.  Plain scalars such as yes, on, 010 or 3.10 are read as booleans and numbers by
+		// the loader, and quoting them would change the configuration.
+		if child.Kind == yaml.ScalarNode && child.Style == 0 && ambiguousString.MatchString(child.Value) && loadsAsString(child.Value, child.Value) {
+			child.Style = yaml.DoubleQuotedStyle
+		}
+
+		quoteAmbiguousStrings(child)
+	}
+}
+
+// separateTopLevelKeys adds a blank line before each top-level key, and its comments, that follows nested content
+func separateTopLevelKeys(data []byte) []byte {
+	var out []string
+
+	lines := strings.SplitAfter(string(data), "\n")
+	for idx, line := range lines {
+		if idx > 0 && len(line) > 0 && line[0] != ' ' && line[0] != '-' && line[0] != '\n' {
+			previous := lines[idx-1]
+			if len(previous) > 0 && (previous[0] == ' ' || previous[0] == '-') {
+				out = append(out, "\n")
+			}
+		}
+		out = append(out, line)
+	}
+
+	return []byte(strings.Join(out, ""))
+}
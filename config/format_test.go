@@ -0,0 +1,124 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	source := `---
+# The releases
+charts:
+  - version: 1.3.1
+    # Second
+    release: web
+    namespace: b
+    name: nginx
+    values:
+      imageTag: 3.10.0
+      enabled: yes
+      replicas: 2
+      tag: "1.0"
+  # First
+  - namespace: a
+    release: db
+    name: mariadb
+repositories:
+  - url: https://charts.example.com
+    name: example
+`
+
+	want := `---
+# The releases
+charts:
+  # First
+  - name: mariadb
+    namespace: a
+    release: db
+  - name: nginx
+    namespace: b
+    # Second
+    release: web
+    values:
+      imageTag: "3.10.0"
+      enabled: yes
+      replicas: 2
+      tag: "1.0"
+    version: "1.3.1"
+
+repositories:
+  - name: example
+    url: https://charts.example.com
+`
+
+	got, err := Format([]byte(source))
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if string(got) != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+
+	again, err := Format(got)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if string(again) != string(got) {
+		t.Errorf("want formatting to be idempotent, got:\n%s", again)
+	}
+}
+
+func TestFormat_Invalid(t *testing.T) {
+	if _, err := Format([]byte("- a\n- b\n")); err == nil {
+		t.Errorf("want an error for a config file that is not a mapping, but was nil")
+	}
+}
+
+func TestFormat_KeepsLoadedConfig(t *testing.T) {
+	source, err := os.ReadFile("../testdata/fmt.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	formatted, err := Format(source)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	before, err := LoadAndValidate("fmt.yml", source)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	after, err := LoadAndValidate("fmt.yml", formatted)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(before.View(), after.View()) {
+		t.Errorf("want the loaded config to be unchanged by formatting, got:\n%v\nbefore:\n%v", after.View(), before.View())
+	}
+}
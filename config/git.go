@@ -0,0 +1,61 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+)
+
+// GitSource is a chart stored in a git repository
+type GitSource struct {
+	// Path is the directory of the chart within the repository
+	Path string `mapstructure:"path"`
+	// Ref is the branch, tag or commit that is checked out
+	Ref string `mapstructure:"ref"`
+	// URL is the url of the git repository
+	URL string `mapstructure:"url"`
+}
+
+// Empty returns if no git source is given
+func (g GitSource) Empty() bool {
+	return len(g.URL) == 0 && len(g.Ref) == 0 && len(g.Path) == 0
+}
+
+func (g GitSource) String() string {
+	var path string
+	if len(g.Path) > 0 {
+		path = "//" + g.Path
+	}
+
+	return fmt.Sprintf("%s%s@%s", g.URL, path, g.Ref)
+}
+
+func (g GitSource) validate() error {
+	if len(g.URL) == 0 {
+		return fmt.Errorf("validating git source: url is required")
+	}
+
+	if len(g.Ref) == 0 {
+		return fmt.Errorf("validating git source %s: ref is required", g.URL)
+	}
+
+	return nil
+}
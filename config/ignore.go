@@ -0,0 +1,109 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// PathWildcard matches any map key or list element within a field path
+const PathWildcard = "*"
+
+// DiffIgnoreRule definition
+//
+// A rule strips the fields matching its paths from every resource selected by kind and name before
+// resources are compared.  A rule without any paths ignores the selected resources entirely.
+type DiffIgnoreRule struct {
+	// Kind selects resources by their kind, glob patterns are supported
+	Kind string `mapstructure:"kind"`
+	// Name selects resources by their name, glob patterns are supported
+	Name string `mapstructure:"name"`
+	// Paths are the JSONPath-like paths of the fields that are stripped.  Without paths, the resources are ignored.
+	Paths []string `mapstructure:"paths"`
+}
+
+// Matches returns if the rule selects a resource with the given kind and name.  The selectors support
+// shell glob patterns and an empty selector matches everything.
+func (r DiffIgnoreRule) Matches(kind string, name string) bool {
+	if len(r.Kind) > 0 {
+		if ok, _ := path.Match(r.Kind, kind); !ok {
+			return false
+		}
+	}
+
+	if len(r.Name) > 0 {
+		if ok, _ := path.Match(r.Name, name); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseFieldPath splits a JSONPath-like field path into its segments.  Segments are separated by dots
+// and keys containing dots or slashes can be given in brackets, for example:
+//
+//	metadata.annotations["checksum/config"]
+//	spec.template.spec.containers[*].env
+//	$.metadata.labels['helm.sh/chart']
+func ParseFieldPath(p string) ([]string, error) {
+	var segments []string
+
+	s := strings.TrimPrefix(strings.TrimPrefix(p, "$"), ".")
+	if len(s) == 0 {
+		return nil, fmt.Errorf("parsing field path %q: path is empty", p)
+	}
+
+	for len(s) > 0 {
+		switch s[0] {
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("parsing field path %q: missing closing bracket", p)
+			}
+			key := s[1:end]
+			if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') && key[len(key)-1] == key[0] {
+				key = key[1 : len(key)-1]
+			}
+			if len(key) == 0 {
+				return nil, fmt.Errorf("parsing field path %q: empty key in brackets", p)
+			}
+			segments = append(segments, key)
+			s = s[end+1:]
+		case '.':
+			s = s[1:]
+			if len(s) == 0 || s[0] == '.' {
+				return nil, fmt.Errorf("parsing field path %q: empty segment", p)
+			}
+		default:
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			segments = append(segments, s[:end])
+			s = s[end:]
+		}
+	}
+
+	return segments, nil
+}
@@ -0,0 +1,105 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestParseFieldPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"metadata.name", []string{"metadata", "name"}},
+		{`metadata.annotations["checksum/config"]`, []string{"metadata", "annotations", "checksum/config"}},
+		{"$.metadata.labels['helm.sh/chart']", []string{"metadata", "labels", "helm.sh/chart"}},
+		{"spec.containers[*].image", []string{"spec", "containers", "*", "image"}},
+		{".data[0]", []string{"data", "0"}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFieldPath(tt.path)
+		if err != nil {
+			t.Errorf("want no error for %s, got %v", tt.path, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("want %s to parse as %#v, got %#v", tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestParseFieldPath_Invalid(t *testing.T) {
+	for _, p := range []string{"", "$", "metadata..name", "metadata.", "metadata[name", "metadata[]"} {
+		if _, err := ParseFieldPath(p); err == nil {
+			t.Errorf("want an error for %q, but was nil", p)
+		}
+	}
+}
+
+func TestDiffIgnoreRuleMatches(t *testing.T) {
+	rule := DiffIgnoreRule{Kind: "Secret", Name: "*-tls"}
+
+	if !rule.Matches("Secret", "web-tls") {
+		t.Errorf("want %#v to match Secret web-tls", rule)
+	}
+
+	if rule.Matches("Secret", "web") {
+		t.Errorf("want %#v to NOT match Secret web", rule)
+	}
+
+	if !(DiffIgnoreRule{}).Matches("ConfigMap", "web") {
+		t.Errorf("want an empty rule to match everything")
+	}
+}
+
+func TestLoadAndValidateFromViper_DiffIgnore(t *testing.T) {
+	viper.SetConfigFile("../testdata/diff-ignore.yml")
+	viper.ReadInConfig()
+
+	c, err := LoadAndValidateFromViper()
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if len(c.DiffIgnore) != 1 || len(c.Charts[0].DiffIgnore) != 1 {
+		t.Fatalf("want global and chart diffIgnore rules, got %#v and %#v", c.DiffIgnore, c.Charts[0].DiffIgnore)
+	}
+
+	got := c.Charts[0].DiffIgnore[0].Kind
+	want := "Secret"
+	if got != want {
+		t.Errorf("want rule kind %s, got %s", want, got)
+	}
+}
+
+func TestLoadAndValidateFromViper_InvalidDiffIgnore(t *testing.T) {
+	viper.SetConfigFile("../testdata/invalid-diff-ignore.yml")
+	viper.ReadInConfig()
+
+	_, err := LoadAndValidateFromViper()
+	if err == nil {
+		t.Errorf("want an error for an invalid diffIgnore path, but was nil")
+	}
+}
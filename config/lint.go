@@ -0,0 +1,182 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValuesIssue is a problem found when comparing chart values with the defaults of the chart
+type ValuesIssue struct {
+	Path       string
+	Message    string
+	Suggestion string
+}
+
+func (i ValuesIssue) String() string {
+	if len(i.Suggestion) > 0 {
+		return fmt.Sprintf("%s: %s, did you mean %s?", i.Path, i.Message, i.Suggestion)
+	}
+	return fmt.Sprintf("%s: %s", i.Path, i.Message)
+}
+
+// GlobalValues is the key of the values that helm shares between a chart and its subcharts
+const GlobalValues = "global"
+
+// LintValues compares the values with the default values of a chart and reports keys that do not exist in
+// the defaults and values that change type.  Empty maps and null values within the defaults are treated as
+// free-form, as are the paths given in freeform and the global values, which helm shares with subcharts.
+func LintValues(defaults map[string]any, values map[string]any, freeform []string) []ValuesIssue {
+	var issues []ValuesIssue
+
+	freeform = append([]string{GlobalValues}, freeform...)
+	lintValues(defaults, values, "", freeform, &issues)
+
+	sort.Slice(issues, func(i, j int) bool {
+		return issues[i].Path < issues[j].Path
+	})
+
+	return issues
+}
+
+func lintValues(defaults map[string]any, values map[string]any, prefix string, freeform []string, issues *[]ValuesIssue) {
+	for k, v := range values {
+		path := joinValuePath(prefix, k)
+
+		if isFreeform(path, freeform) {
+			continue
+		}
+
+		dv, ok := defaults[k]
+		if !ok {
+			issue := ValuesIssue{Path: path, Message: "key does not exist in the chart defaults"}
+			if closest := closestKey(k, defaults); len(closest) > 0 {
+				issue.Suggestion = joinValuePath(prefix, closest)
+			}
+			*issues = append(*issues, issue)
+			continue
+		}
+
+		// A null value removes the key, and a null default can hold anything
+		if v == nil || dv == nil {
+			continue
+		}
+
+		defaultType, valueType := valueType(dv), valueType(v)
+		if defaultType != valueType {
+			*issues = append(*issues, ValuesIssue{
+				Path:    path,
+				Message: fmt.Sprintf("type changes from %s to %s", defaultType, valueType),
+			})
+			continue
+		}
+
+		dm, _ := dv.(map[string]any)
+		vm, _ := v.(map[string]any)
+		if len(dm) > 0 {
+			lintValues(dm, vm, path, freeform, issues)
+		}
+	}
+}
+
+func isFreeform(path string, freeform []string) bool {
+	for _, f := range freeform {
+		if path == f || strings.HasPrefix(path, f+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func valueType(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "map"
+	case []any:
+		return "list"
+	case bool:
+		return "boolean"
+	case int, int64, uint64, float64:
+		return "number"
+	case string:
+		return "string"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// closestKey returns the key of the map that is most similar to the given key, if any is close enough
+func closestKey(key string, m map[string]any) string {
+	var closest string
+	best := len(key)/3 + 1
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if strings.EqualFold(k, key) {
+			return k
+		}
+
+		if d := levenshtein(strings.ToLower(key), strings.ToLower(k)); d <= best {
+			if d < best || len(closest) == 0 {
+				closest = k
+				best = d
+			}
+		}
+	}
+
+	return closest
+}
+
+func levenshtein(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j] + 1
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+			if prev[j-1]+cost < curr[j] {
+				curr[j] = prev[j-1] + cost
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
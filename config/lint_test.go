@@ -0,0 +1,80 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintValues(t *testing.T) {
+	defaults := map[string]any{
+		"replicaCount":   1,
+		"image":          map[string]any{"repository": "web", "tag": ""},
+		"podAnnotations": map[string]any{},
+		"resources":      nil,
+		"ingress":        map[string]any{"enabled": false, "hosts": []any{}},
+		"config":         map[string]any{"log": "info"},
+	}
+
+	values := map[string]any{
+		"replicacount":   3,
+		"image":          map[string]any{"tag": 1, "pullPolicy": "Always"},
+		"podAnnotations": map[string]any{"prometheus.io/scrape": "true"},
+		"resources":      map[string]any{"limits": map[string]any{"cpu": "1"}},
+		"ingress":        map[string]any{"enabled": true, "hosts": "web.example.com"},
+		"config":         map[string]any{"extra": map[string]any{"a": "b"}},
+		"tolerations":    []any{},
+		"global":         map[string]any{"imageRegistry": "registry.example.com"},
+		"postgresql":     map[string]any{"auth": map[string]any{"database": "web"}},
+	}
+
+	got := LintValues(defaults, values, []string{"config.extra", "postgresql"})
+	want := []ValuesIssue{
+		{Path: "image.pullPolicy", Message: "key does not exist in the chart defaults"},
+		{Path: "image.tag", Message: "type changes from string to number"},
+		{Path: "ingress.hosts", Message: "type changes from list to string"},
+		{Path: "replicacount", Message: "key does not exist in the chart defaults", Suggestion: "replicaCount"},
+		{Path: "tolerations", Message: "key does not exist in the chart defaults"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want issues:\n%v\ngot:\n%v", want, got)
+	}
+}
+
+func TestClosestKey(t *testing.T) {
+	m := map[string]any{"replicaCount": 1, "image": nil, "service": nil}
+
+	tests := map[string]string{
+		"replicas":     "",
+		"imgae":        "image",
+		"servcie":      "service",
+		"REPLICACOUNT": "replicaCount",
+		"foo":          "",
+	}
+
+	for key, want := range tests {
+		if got := closestKey(key, m); got != want {
+			t.Errorf("want closest key to %s to be %q, got %q", key, want, got)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// versionSuffix matches the version that packaged charts append to their name, such as -1.0.0 in konga-1.0.0.tgz
+var versionSuffix = regexp.MustCompile(`-v?[0-9]+\.[0-9]+\.[0-9]+.*$`)
+
+// Migrate rewrites older layouts of the configuration into the current one, keeping comments and formatting, and
+// returns a description of each change.  The migrations are:
+//
+//   - Charts downloaded from a url that is given as their name, from before the url field, are given by url.
+//   - The apiVersion is added.
+func Migrate(d *Document) ([]string, error) {
+	var changes []string
+
+	if d.root != nil {
+		if _, version := mappingEntry(d.root, "apiVersion"); version != nil && version.Value != APIVersion {
+			return nil, fmt.Errorf("migrating config file: unsupported apiVersion %q, expected %s", version.Value, APIVersion)
+		}
+	}
+
+	// The document is parsed again after each edit, so the charts are looked up by index every time
+	for idx := 0; idx < len(d.items("charts")); idx++ {
+		chart := d.items("charts")[idx]
+
+		chartURL := scalarValue(chart, "name")
+		if len(scalarValue(chart, "repo")) > 0 || len(scalarValue(chart, "url")) > 0 ||
+			!(strings.HasPrefix(chartURL, "https://") || strings.HasPrefix(chartURL, "http://")) {
+			continue
+		}
+
+		name := chartNameFromURL(chartURL)
+		if err := d.SetString(chart, []string{"name"}, name); err != nil {
+			return nil, fmt.Errorf("migrating chart url: %w", err)
+		}
+
+		if err := d.SetString(d.items("charts")[idx], []string{"url"}, chartURL); err != nil {
+			return nil, fmt.Errorf("migrating chart url: %w", err)
+		}
+
+		chart = d.items("charts")[idx]
+		changes = append(changes, fmt.Sprintf("moved the url of release %s/%s from name to url, and named the chart %s",
+			scalarValue(chart, "namespace"), scalarValue(chart, "release"), name))
+	}
+
+	if d.root == nil || scalarValue(d.root, "apiVersion") != APIVersion {
+		if err := d.PrependEntry("apiVersion", APIVersion); err != nil {
+			return nil, fmt.Errorf("migrating apiVersion: %w", err)
+		}
+		changes = append(changes, "added apiVersion "+APIVersion)
+	}
+
+	return changes, nil
+}
+
+// chartNameFromURL returns the chart name of a packaged chart url, which is its file name without the version
+// and extension
+func chartNameFromURL(chartURL string) string {
+	name := chartURL
+	if u, err := url.Parse(chartURL); err == nil {
+		name = path.Base(u.Path)
+	}
+
+	name = strings.TrimSuffix(name, ".tgz")
+	if stripped := versionSuffix.ReplaceAllString(name, ""); len(stripped) > 0 {
+		name = stripped
+	}
+
+	return name
+}
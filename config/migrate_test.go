@@ -0,0 +1,92 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	source := `---
+# The releases
+charts:
+  # Konga is downloaded from github
+  - name: https://github.com/pantsel/konga/blob/master/charts/konga/konga-1.0.0.tgz?raw=true
+    namespace: kube-system
+    release: konga
+    state: present
+  - name: concourse
+    namespace: apps
+    release: apps-concourse
+    repo: stable
+`
+
+	want := `---
+apiVersion: binnacle/v1
+
+# The releases
+charts:
+  # Konga is downloaded from github
+  - name: konga
+    namespace: kube-system
+    release: konga
+    state: present
+    url: https://github.com/pantsel/konga/blob/master/charts/konga/konga-1.0.0.tgz?raw=true
+  - name: concourse
+    namespace: apps
+    release: apps-concourse
+    repo: stable
+`
+
+	d, err := ParseDocument([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := Migrate(d)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if got := string(d.Bytes()); got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+
+	if len(changes) != 2 {
+		t.Errorf("want 2 changes, got %v", changes)
+	}
+
+	changes, err = Migrate(d)
+	if err != nil || len(changes) != 0 {
+		t.Errorf("want a migrated config file to be left alone, got %v, %v", changes, err)
+	}
+}
+
+func TestMigrate_UnsupportedAPIVersion(t *testing.T) {
+	d, err := ParseDocument([]byte("apiVersion: binnacle/v2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Migrate(d); err == nil {
+		t.Errorf("want an error for an unsupported apiVersion, but was nil")
+	}
+}
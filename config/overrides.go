@@ -0,0 +1,181 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Kinds of value overrides, named after the matching helm flags
+const (
+	OverrideSet       = "--set"
+	OverrideSetString = "--set-string"
+	OverrideSetFile   = "--set-file"
+)
+
+// ValueOverride is a single value set on the command line for one release
+type ValueOverride struct {
+	// Release is the release the value applies to, as namespace/release or release
+	Release string
+	// Path is the path of the value within the chart values
+	Path []string
+	// Value is the parsed value
+	Value any
+	// Source is the override as given on the command line
+	Source string
+}
+
+// ParseValueOverride parses an override given as namespace/release:key.path=value.  Depending on the kind
+// of override the value is typed like helm's --set does, kept as a string, or read from a file.  Dots
+// within keys and commas within values can be escaped with a backslash.  Unlike helm, each override sets a
+// single value: multiple comma separated values, {a,b} lists and list indexes are rejected.
+func ParseValueOverride(kind string, s string) (ValueOverride, error) {
+	var o ValueOverride
+	o.Source = kind + " " + s
+
+	release, assignment, found := strings.Cut(s, ":")
+	if !found || len(release) == 0 {
+		return o, fmt.Errorf("parsing %s %s: expected namespace/release:key=value", kind, s)
+	}
+	o.Release = release
+
+	key, value, found := strings.Cut(assignment, "=")
+	if !found || len(key) == 0 {
+		return o, fmt.Errorf("parsing %s %s: expected namespace/release:key=value", kind, s)
+	}
+
+	if strings.ContainsAny(key, "[]") {
+		return o, fmt.Errorf("parsing %s %s: list indexes are not supported", kind, s)
+	}
+
+	if kind != OverrideSetFile {
+		if hasUnescapedComma(value) {
+			return o, fmt.Errorf("parsing %s %s: multiple values are not supported, use a separate %s for each value or escape the comma with a backslash", kind, s, kind)
+		}
+
+		if strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}") {
+			return o, fmt.Errorf("parsing %s %s: lists are not supported, set the list in the config file", kind, s)
+		}
+
+		value = strings.ReplaceAll(value, `\,`, ",")
+	}
+
+	o.Path = splitValuePath(key)
+	for _, segment := range o.Path {
+		if len(segment) == 0 {
+			return o, fmt.Errorf("parsing %s %s: key %s contains an empty segment", kind, s, key)
+		}
+	}
+
+	switch kind {
+	case OverrideSet:
+		o.Value = typedValue(value)
+	case OverrideSetString:
+		o.Value = value
+	case OverrideSetFile:
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return o, fmt.Errorf("parsing %s %s: %w", kind, s, err)
+		}
+		o.Value = string(data)
+	default:
+		return o, fmt.Errorf("parsing %s %s: unknown kind of override", kind, s)
+	}
+
+	return o, nil
+}
+
+// Matches returns if the override applies to the given chart
+func (o ValueOverride) Matches(chart ChartConfig) bool {
+	namespace, release, hasNamespace := strings.Cut(o.Release, "/")
+	if !hasNamespace {
+		return chart.Release == o.Release
+	}
+	return chart.Namespace == namespace && chart.Release == release
+}
+
+// Values returns the override as a nested map of values
+func (o ValueOverride) Values() map[string]any {
+	values := map[string]any{o.Path[len(o.Path)-1]: o.Value}
+	for i := len(o.Path) - 2; i >= 0; i-- {
+		values = map[string]any{o.Path[i]: values}
+	}
+	return values
+}
+
+func splitValuePath(key string) []string {
+	var path []string
+	var segment strings.Builder
+
+	for i := 0; i < len(key); i++ {
+		switch {
+		case key[i] == '\\' && i+1 < len(key) && key[i+1] == '.':
+			segment.WriteByte('.')
+			i++
+		case key[i] == '.':
+			path = append(path, segment.String())
+			segment.Reset()
+		default:
+			segment.WriteByte(key[i])
+		}
+	}
+
+	return append(path, segment.String())
+}
+
+// hasUnescapedComma returns if the value contains a comma that is not escaped with a backslash
+func hasUnescapedComma(value string) bool {
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case ',':
+			return true
+		}
+	}
+	return false
+}
+
+// typedValue converts a value the same way helm's --set does
+func typedValue(value string) any {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+
+	// Keep values with leading zeros, like version numbers, as strings
+	if len(value) > 1 && strings.HasPrefix(value, "0") {
+		return value
+	}
+
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return i
+	}
+
+	return value
+}
@@ -0,0 +1,103 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseValueOverride(t *testing.T) {
+	tests := []struct {
+		kind  string
+		s     string
+		path  []string
+		value any
+	}{
+		{OverrideSet, "apps/web:image.tag=1.4.2", []string{"image", "tag"}, "1.4.2"},
+		{OverrideSet, "apps/web:replicas=3", []string{"replicas"}, int64(3)},
+		{OverrideSet, "web:ingress.enabled=false", []string{"ingress", "enabled"}, false},
+		{OverrideSet, "web:zip=0123", []string{"zip"}, "0123"},
+		{OverrideSet, `web:podAnnotations.prometheus\.io/scrape=a=b`, []string{"podAnnotations", "prometheus.io/scrape"}, "a=b"},
+		{OverrideSetString, "apps/web:replicas=3", []string{"replicas"}, "3"},
+		{OverrideSetString, `apps/web:hosts=a\,b`, []string{"hosts"}, "a,b"},
+	}
+
+	for _, tt := range tests {
+		o, err := ParseValueOverride(tt.kind, tt.s)
+		if err != nil {
+			t.Errorf("want no error for %s, got %v", tt.s, err)
+			continue
+		}
+		if !reflect.DeepEqual(o.Path, tt.path) {
+			t.Errorf("want %s to have path %#v, got %#v", tt.s, tt.path, o.Path)
+		}
+		if o.Value != tt.value {
+			t.Errorf("want %s to have value %#v, got %#v", tt.s, tt.value, o.Value)
+		}
+	}
+}
+
+func TestParseValueOverride_File(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "script.sh")
+	os.WriteFile(file, []byte("#!/bin/sh\n"), 0644)
+
+	o, err := ParseValueOverride(OverrideSetFile, "apps/web:script="+file)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := map[string]any{"script": "#!/bin/sh\n"}
+	if !reflect.DeepEqual(o.Values(), want) {
+		t.Errorf("want values %#v, got %#v", want, o.Values())
+	}
+}
+
+func TestParseValueOverride_Invalid(t *testing.T) {
+	for _, s := range []string{"image.tag=1", ":image.tag=1", "apps/web:image.tag", "apps/web:=1", "apps/web:image..tag=1"} {
+		if _, err := ParseValueOverride(OverrideSet, s); err == nil {
+			t.Errorf("want an error for %s, but was nil", s)
+		}
+	}
+}
+
+func TestParseValueOverride_UnsupportedSyntax(t *testing.T) {
+	for _, s := range []string{"apps/web:a=1,b=2", "apps/web:hosts={a,b}", "apps/web:hosts={a}", "apps/web:hosts[0]=a"} {
+		for _, kind := range []string{OverrideSet, OverrideSetString} {
+			if _, err := ParseValueOverride(kind, s); err == nil {
+				t.Errorf("want an error for %s %s, but was nil", kind, s)
+			}
+		}
+	}
+}
+
+func TestValueOverrideMatches(t *testing.T) {
+	chart := ChartConfig{Namespace: "apps", Release: "web"}
+
+	for ref, want := range map[string]bool{"apps/web": true, "web": true, "other/web": false, "api": false} {
+		o := ValueOverride{Release: ref}
+		if got := o.Matches(chart); got != want {
+			t.Errorf("want %s matching apps/web to be %v, got %v", ref, want, got)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OCIScheme is the prefix of charts and repositories stored in an OCI registry
+const OCIScheme = "oci://"
+
+// RegistryConfig definition
+type RegistryConfig struct {
+	// Host is the host of the registry, optionally prefixed with oci://
+	Host string `mapstructure:"host"`
+	// Insecure allows connecting to the registry over http or without verifying its certificate
+	Insecure bool `mapstructure:"insecure"`
+	// Password is the password used to log in to the registry
+	Password SecretRef `mapstructure:"password"`
+	// Username is the username used to log in to the registry
+	Username SecretRef `mapstructure:"username"`
+}
+
+// Credentials resolves the username and password of the registry.  Relative file paths are resolved against
+// the given directory.
+func (c RegistryConfig) Credentials(dir string) (string, string, error) {
+	username, err := c.Username.Resolve(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("reading username of registry %s: %w", c.Host, err)
+	}
+
+	password, err := c.Password.Resolve(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("reading password of registry %s: %w", c.Host, err)
+	}
+
+	return username, password, nil
+}
+
+// LoginHost returns the host given to `helm registry login`, without the oci:// scheme
+func (c RegistryConfig) LoginHost() string {
+	return strings.TrimPrefix(c.Host, OCIScheme)
+}
+
+func (c RegistryConfig) validate() error {
+	if len(c.Host) == 0 {
+		return fmt.Errorf("validating registry: host is required")
+	}
+
+	if err := c.Username.validate(); err != nil {
+		return fmt.Errorf("validating username of registry %s: %w", c.Host, err)
+	}
+
+	if err := c.Password.validate(); err != nil {
+		return fmt.Errorf("validating password of registry %s: %w", c.Host, err)
+	}
+
+	if c.Username.Empty() || c.Password.Empty() {
+		return fmt.Errorf("validating registry %s: username and password are required", c.Host)
+	}
+
+	return nil
+}
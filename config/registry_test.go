@@ -0,0 +1,67 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestRegistryConfigValidate(t *testing.T) {
+	tests := []struct {
+		registry RegistryConfig
+		valid    bool
+	}{
+		{RegistryConfig{Host: "registry.example.com", Username: SecretRef{Env: "USER"}, Password: SecretRef{Env: "PASS"}}, true},
+		{RegistryConfig{Username: SecretRef{Env: "USER"}, Password: SecretRef{Env: "PASS"}}, false},
+		{RegistryConfig{Host: "registry.example.com", Username: SecretRef{Env: "USER"}}, false},
+		{RegistryConfig{Host: "registry.example.com", Username: SecretRef{Env: "USER"}, Password: SecretRef{Env: "PASS", File: "pass"}}, false},
+	}
+
+	for _, test := range tests {
+		err := test.registry.validate()
+		if test.valid && err != nil {
+			t.Errorf("want no error for %#v, got %v", test.registry, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("want an error for %#v, but was nil", test.registry)
+		}
+	}
+}
+
+func TestRegistryLoginHost(t *testing.T) {
+	registry := RegistryConfig{Host: "oci://registry.example.com"}
+
+	if got := registry.LoginHost(); got != "registry.example.com" {
+		t.Errorf("want login host registry.example.com, got %s", got)
+	}
+}
+
+func TestLoadAndValidateFromViper_OCIRepository(t *testing.T) {
+	viper.SetConfigFile("../testdata/invalid-oci-repo.yml")
+	viper.ReadInConfig()
+
+	_, err := LoadAndValidateFromViper()
+	if err == nil {
+		t.Errorf("want an error for an oci:// repository, but was nil")
+	}
+}
@@ -20,11 +20,145 @@
 
 package config
 
+import (
+	"fmt"
+	"strings"
+)
+
 // RepositoryConfig definition
 type RepositoryConfig struct {
-	Name  string `mapstructure:"name"`
+	// CAFile is the CA bundle used to verify the certificate of the repository
+	CAFile string `mapstructure:"caFile"`
+	// CertFile is the client certificate used to authenticate to the repository
+	CertFile string `mapstructure:"certFile"`
+	// InsecureSkipTLSVerify skips the verification of the certificate of the repository
+	InsecureSkipTLSVerify bool `mapstructure:"insecureSkipTLSverify"`
+	// KeyFile is the key of the client certificate
+	KeyFile string `mapstructure:"keyFile"`
+	// Name is the name of the repository, which charts refer to with repo
+	Name string `mapstructure:"name"`
+	// PassCredentials passes the credentials to all domains, for charts that are hosted elsewhere
+	PassCredentials bool `mapstructure:"passCredentials"`
+	// Password is the password used to authenticate to the repository
+	Password SecretRef `mapstructure:"password"`
+	// State determines if the repository is added or removed
 	State string `mapstructure:"state"`
-	URL   string `mapstructure:"url"`
+	// URL is the url of the repository
+	URL string `mapstructure:"url"`
+	// Username is the username used to authenticate to the repository
+	Username SecretRef `mapstructure:"username"`
+}
+
+// Credentials resolves the username and password of the repository.  Relative file paths are resolved against
+// the given directory.
+func (c RepositoryConfig) Credentials(dir string) (string, string, error) {
+	username, err := c.Username.Resolve(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("reading username of repository %s: %w", c.Name, err)
+	}
+
+	password, err := c.Password.Resolve(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("reading password of repository %s: %w", c.Name, err)
+	}
+
+	return username, password, nil
+}
+
+// HelmRepository is a repository as helm stores it in its repositories file, with the credentials and file paths
+// resolved
+type HelmRepository struct {
+	CAFile                string `yaml:"caFile"`
+	CertFile              string `yaml:"certFile"`
+	InsecureSkipTLSVerify bool   `yaml:"insecure_skip_tls_verify"`
+	KeyFile               string `yaml:"keyFile"`
+	Name                  string `yaml:"name"`
+	PassCredentials       bool   `yaml:"pass_credentials_all"`
+	Password              string `yaml:"password"`
+	URL                   string `yaml:"url"`
+	Username              string `yaml:"username"`
+}
+
+// HelmRepository returns the repository as helm stores it once it is added.  Relative file paths are resolved
+// against the given directory.
+func (c RepositoryConfig) HelmRepository(dir string) (HelmRepository, error) {
+	username, password, err := c.Credentials(dir)
+	if err != nil {
+		return HelmRepository{}, err
+	}
+
+	return HelmRepository{
+		CAFile:                resolvePath(dir, c.CAFile),
+		CertFile:              resolvePath(dir, c.CertFile),
+		InsecureSkipTLSVerify: c.InsecureSkipTLSVerify,
+		KeyFile:               resolvePath(dir, c.KeyFile),
+		Name:                  c.Name,
+		PassCredentials:       c.PassCredentials,
+		Password:              password,
+		URL:                   c.URL,
+		Username:              username,
+	}, nil
+}
+
+// AddArgs returns the `helm repo add` flags for the TLS and authentication options of the repository, with the
+// given credentials.  The password is not part of the flags, it is read from stdin with --password-stdin.
+// Relative file paths are resolved against the given directory.
+func (c RepositoryConfig) AddArgs(dir string, username string, password string) []string {
+	var args []string
+
+	if len(username) > 0 {
+		args = append(args, "--username", username)
+	}
+
+	if len(password) > 0 {
+		args = append(args, "--password-stdin")
+	}
+
+	if len(c.CAFile) > 0 {
+		args = append(args, "--ca-file", resolvePath(dir, c.CAFile))
+	}
+
+	if len(c.CertFile) > 0 {
+		args = append(args, "--cert-file", resolvePath(dir, c.CertFile))
+	}
+
+	if len(c.KeyFile) > 0 {
+		args = append(args, "--key-file", resolvePath(dir, c.KeyFile))
+	}
+
+	if c.InsecureSkipTLSVerify {
+		args = append(args, "--insecure-skip-tls-verify")
+	}
+
+	if c.PassCredentials {
+		args = append(args, "--pass-credentials")
+	}
+
+	return args
+}
+
+func (c RepositoryConfig) validate() error {
+	if strings.HasPrefix(c.URL, OCIScheme) {
+		return fmt.Errorf("validating repository %s: OCI registries are not chart repositories, use an oci:// chart name and the registries section instead", c.Name)
+	}
+
+	if err := c.Username.validate(); err != nil {
+		return fmt.Errorf("validating username of repository %s: %w", c.Name, err)
+	}
+
+	if err := c.Password.validate(); err != nil {
+		return fmt.Errorf("validating password of repository %s: %w", c.Name, err)
+	}
+
+	if c.Username.Empty() != c.Password.Empty() {
+		return fmt.Errorf("validating repository %s: username and password must be set together", c.Name)
+	}
+
+	if (len(c.CertFile) > 0) != (len(c.KeyFile) > 0) {
+		return fmt.Errorf("validating repository %s: certFile and keyFile must be set together", c.Name)
+	}
+
+	return nil
 }
 
 // Equal comparison operator
@@ -21,7 +21,11 @@
 package config
 
 import (
+	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/spf13/viper"
 )
 
 func TestRepositoryEquals_NamesDoNotMatch(t *testing.T) {
@@ -59,3 +63,88 @@ func TestRepositoryEquals(t *testing.T) {
 		t.Errorf("want %#v to equal %#v", rep1, rep2)
 	}
 }
+
+func TestRepositoryAddArgs(t *testing.T) {
+	t.Setenv("BINNACLE_TEST_REPO_USERNAME", "deploy")
+
+	viper.SetConfigFile("../testdata/private-repo.yml")
+	viper.ReadInConfig()
+	c, err := LoadAndValidateFromViper()
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	repo := c.Repositories[0]
+	dir := filepath.Dir(c.ConfigFile)
+
+	username, password, err := repo.Credentials(dir)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if username != "deploy" || password != "s3cr3t" {
+		t.Errorf("want credentials deploy/s3cr3t, got %s/%s", username, password)
+	}
+
+	got := repo.AddArgs(dir, username, password)
+	want := []string{
+		"--username", "deploy",
+		"--password-stdin",
+		"--ca-file", filepath.Join(dir, "certs/ca.pem"),
+		"--cert-file", "/etc/binnacle/client.pem",
+		"--key-file", "/etc/binnacle/client-key.pem",
+		"--pass-credentials",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want args %v, got %v", want, got)
+	}
+}
+
+func TestRepositoryHelmRepository(t *testing.T) {
+	t.Setenv("BINNACLE_TEST_REPO_USERNAME", "deploy")
+
+	viper.SetConfigFile("../testdata/private-repo.yml")
+	viper.ReadInConfig()
+	c, err := LoadAndValidateFromViper()
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	dir := filepath.Dir(c.ConfigFile)
+
+	got, err := c.Repositories[0].HelmRepository(dir)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := HelmRepository{
+		CAFile:          filepath.Join(dir, "certs/ca.pem"),
+		CertFile:        "/etc/binnacle/client.pem",
+		KeyFile:         "/etc/binnacle/client-key.pem",
+		Name:            "private",
+		PassCredentials: true,
+		Password:        "s3cr3t",
+		URL:             "https://charts.example.com/private",
+		Username:        "deploy",
+	}
+	if got != want {
+		t.Errorf("want %#v, got %#v", want, got)
+	}
+}
+
+func TestRepositoryCredentials_MissingEnv(t *testing.T) {
+	repo := RepositoryConfig{Name: "private", Username: SecretRef{Env: "BINNACLE_TEST_UNSET_VARIABLE"}}
+
+	if _, _, err := repo.Credentials("."); err == nil {
+		t.Errorf("want an error for an unset environment variable, but was nil")
+	}
+}
+
+func TestLoadAndValidateFromViper_InvalidRepository(t *testing.T) {
+	viper.SetConfigFile("../testdata/invalid-private-repo.yml")
+	viper.ReadInConfig()
+
+	_, err := LoadAndValidateFromViper()
+	if err == nil {
+		t.Errorf("want an error for a username without a password, but was nil")
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Schema is the JSON Schema of the configuration file.  It is generated from the configuration types, and kept
+// up to date by the tests of this package.
+//
+//go:embed schema.json
+var Schema []byte
+
+// SchemaDocs are the descriptions of the fields of the configuration types, keyed by type and field, such as
+// ChartConfig.Version
+type SchemaDocs map[string]string
+
+// schemaEnums are the allowed values of fields, keyed by type and field
+var schemaEnums = map[string][]string{
+	"BinnacleConfig.APIVersion": {APIVersion},
+	"ChartConfig.State":         States,
+	"RepositoryConfig.State":    States,
+}
+
+type schemaGenerator struct {
+	docs        SchemaDocs
+	definitions map[string]any
+}
+
+// GenerateSchema generates the JSON Schema of the configuration file from the configuration types, using the
+// given field descriptions.  Unknown keys are not allowed, just like the config loader does.
+func GenerateSchema(docs SchemaDocs) ([]byte, error) {
+	g := schemaGenerator{docs: docs, definitions: make(map[string]any)}
+
+	root := g.object(reflect.TypeOf(BinnacleConfig{}))
+	root["$schema"] = "http://json-schema.org/draft-07/schema#"
+	root["title"] = "Binnacle configuration"
+	root["definitions"] = g.definitions
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("generating schema: %w", err)
+	}
+
+	return append(data, '\n'), nil
+}
+
+// schema returns the schema of the given type.  Structs are added to the definitions and referenced.
+func (g *schemaGenerator) schema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schema(t.Elem())
+	case reflect.Struct:
+		if _, found := g.definitions[t.Name()]; !found {
+			// Reserve the definition first, in case the type refers to itself
+			g.definitions[t.Name()] = nil
+			g.definitions[t.Name()] = g.object(t)
+		}
+		return map[string]any{"$ref": "#/definitions/" + t.Name()}
+	case reflect.Map:
+		if t.Elem().Kind() == reflect.Interface {
+			return map[string]any{"type": "object"}
+		}
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	default:
+		return map[string]any{}
+	}
+}
+
+// object returns the schema of the struct type
+func (g *schemaGenerator) object(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	g.properties(t, properties)
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// properties adds the fields of the struct type to the properties, by their mapstructure names.  Fields without
+// a mapstructure name, such as the config file, are only set at runtime and are skipped.
+func (g *schemaGenerator) properties(t reflect.Type, properties map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+
+		if strings.Contains(opts, "squash") {
+			g.properties(field.Type, properties)
+			continue
+		}
+
+		if len(name) == 0 || name == "-" {
+			continue
+		}
+
+		key := t.Name() + "." + field.Name
+		schema := g.schema(field.Type)
+
+		if enum, found := schemaEnums[key]; found {
+			schema["enum"] = enum
+		}
+
+		if doc := g.docs[key]; len(doc) > 0 {
+			// Keywords next to a $ref are ignored, so the reference is wrapped
+			if ref, found := schema["$ref"]; found {
+				schema = map[string]any{"allOf": []any{map[string]any{"$ref": ref}}}
+			}
+			schema["description"] = doc
+		}
+
+		properties[name] = schema
+	}
+}
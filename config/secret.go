@@ -0,0 +1,80 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SecretRef references a secret that is read from an environment variable or a file, so that it never has
+// to be written in the configuration file
+type SecretRef struct {
+	// Env is the environment variable the secret is read from
+	Env string `mapstructure:"env"`
+	// File is the file the secret is read from, relative to the config file
+	File string `mapstructure:"file"`
+}
+
+// Empty returns if no secret is referenced
+func (s SecretRef) Empty() bool {
+	return len(s.Env) == 0 && len(s.File) == 0
+}
+
+// Resolve returns the value of the secret.  Relative file paths are resolved against the given directory.
+func (s SecretRef) Resolve(dir string) (string, error) {
+	if len(s.Env) > 0 {
+		value, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return "", fmt.Errorf("resolving secret: environment variable %s is not set", s.Env)
+		}
+		return value, nil
+	}
+
+	if len(s.File) > 0 {
+		data, err := os.ReadFile(resolvePath(dir, s.File))
+		if err != nil {
+			return "", fmt.Errorf("resolving secret: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	return "", nil
+}
+
+func (s SecretRef) validate() error {
+	if len(s.Env) > 0 && len(s.File) > 0 {
+		return fmt.Errorf("validating secret: only one of env or file can be set")
+	}
+
+	return nil
+}
+
+// resolvePath returns the path relative to the given directory, unless it is absolute
+func resolvePath(dir string, path string) string {
+	if len(path) == 0 || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,138 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValuesLayer is a set of chart values along with where they came from.  Layers are merged in order, with
+// later layers taking precedence over earlier ones.
+type ValuesLayer struct {
+	Source string
+	Values map[string]any
+}
+
+// MergeValues deep merges the given layers and returns the resulting values along with the source of each
+// leaf value, keyed by its dotted path.  Lists are treated as leaf values and are replaced, not merged,
+// just like helm does.
+func MergeValues(layers ...ValuesLayer) (map[string]any, map[string]string) {
+	values := make(map[string]any)
+	sources := make(map[string]string)
+
+	for _, layer := range layers {
+		mergeValues(values, layer.Values, "", layer.Source, sources)
+	}
+
+	return values, sources
+}
+
+func mergeValues(dst map[string]any, src map[string]any, prefix string, source string, sources map[string]string) {
+	for k, v := range src {
+		path := joinValuePath(prefix, k)
+
+		srcMap, srcIsMap := v.(map[string]any)
+		dstMap, dstIsMap := dst[k].(map[string]any)
+
+		switch {
+		case srcIsMap && dstIsMap:
+			mergeValues(dstMap, srcMap, path, source, sources)
+		case srcIsMap:
+			// Replace whatever was there before, including the sources of its leaves
+			deleteSources(sources, path)
+			m := make(map[string]any)
+			mergeValues(m, srcMap, path, source, sources)
+			dst[k] = m
+			if len(srcMap) == 0 {
+				sources[path] = source
+			}
+		default:
+			deleteSources(sources, path)
+			dst[k] = v
+			sources[path] = source
+		}
+	}
+}
+
+func deleteSources(sources map[string]string, path string) {
+	for p := range sources {
+		if p == path || strings.HasPrefix(p, path+".") {
+			delete(sources, p)
+		}
+	}
+}
+
+func joinValuePath(prefix string, key string) string {
+	if len(prefix) == 0 {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// ExplainValues returns the values as YAML, with a comment on each leaf value naming its source
+func ExplainValues(values map[string]any, sources map[string]string) (string, error) {
+	node, err := explainNode(values, "", sources)
+	if err != nil {
+		return "", err
+	}
+
+	y, err := yaml.Marshal(node)
+	if err != nil {
+		return "", fmt.Errorf("marshalling chart values: %w", err)
+	}
+
+	return string(y), nil
+}
+
+func explainNode(v any, path string, sources map[string]string) (*yaml.Node, error) {
+	m, isMap := v.(map[string]any)
+	if !isMap || len(m) == 0 {
+		var node yaml.Node
+		if err := node.Encode(v); err != nil {
+			return nil, fmt.Errorf("marshalling chart values: %w", err)
+		}
+		if source, ok := sources[path]; ok {
+			node.LineComment = source
+		}
+		return &node, nil
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, k := range keys {
+		child, err := explainNode(m[k], joinValuePath(path, k), sources)
+		if err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, child)
+	}
+
+	return node, nil
+}
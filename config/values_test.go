@@ -0,0 +1,83 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeValues(t *testing.T) {
+	values, sources := MergeValues(
+		ValuesLayer{Source: "config", Values: map[string]any{
+			"replicas": 1,
+			"image":    map[string]any{"repository": "web", "tag": "1.0.0"},
+			"env":      []any{"A"},
+			"ingress":  map[string]any{"enabled": true},
+		}},
+		ValuesLayer{Source: "--set", Values: map[string]any{
+			"image":   map[string]any{"tag": "1.1.0"},
+			"env":     []any{"B"},
+			"ingress": false,
+		}},
+	)
+
+	want := map[string]any{
+		"replicas": 1,
+		"image":    map[string]any{"repository": "web", "tag": "1.1.0"},
+		"env":      []any{"B"},
+		"ingress":  false,
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("want merged values %#v, got %#v", want, values)
+	}
+
+	wantSources := map[string]string{
+		"replicas":         "config",
+		"image.repository": "config",
+		"image.tag":        "--set",
+		"env":              "--set",
+		"ingress":          "--set",
+	}
+	if !reflect.DeepEqual(sources, wantSources) {
+		t.Errorf("want sources %#v, got %#v", wantSources, sources)
+	}
+}
+
+func TestExplainValues(t *testing.T) {
+	values, sources := MergeValues(ValuesLayer{Source: "demo.yml", Values: map[string]any{
+		"image":    map[string]any{"tag": "1.0.0"},
+		"replicas": 3,
+	}})
+
+	got, err := ExplainValues(values, sources)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := `image:
+    tag: 1.0.0 # demo.yml
+replicas: 3 # demo.yml
+`
+	if got != want {
+		t.Errorf("want explained values:\n%s\ngot:\n%s", want, got)
+	}
+}
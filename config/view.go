@@ -0,0 +1,95 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// View returns the effective configuration, after defaults have been applied, keyed by the names used in the
+// configuration file.  Empty fields are left out.  Secrets are only given by their references, and are never
+// resolved.
+func (c BinnacleConfig) View() map[string]any {
+	view, _ := viewValue(reflect.ValueOf(c)).(map[string]any)
+	return view
+}
+
+// viewValue converts the value into maps, slices and scalars, using the mapstructure names of struct fields.
+// Elements of maps and slices, such as chart values, are kept as they are, even when false, 0 or empty.
+func viewValue(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return viewValue(v.Elem())
+	case reflect.Struct:
+		view := make(map[string]any)
+		viewStruct(v, view)
+		if len(view) == 0 {
+			return nil
+		}
+		return view
+	case reflect.Map:
+		view := make(map[string]any)
+		iter := v.MapRange()
+		for iter.Next() {
+			view[fmt.Sprint(iter.Key().Interface())] = viewValue(iter.Value())
+		}
+		return view
+	case reflect.Slice, reflect.Array:
+		view := make([]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			view = append(view, viewValue(v.Index(i)))
+		}
+		return view
+	default:
+		return v.Interface()
+	}
+}
+
+// viewStruct adds the fields of the struct to the view, leaving out the empty ones.  Fields without a
+// mapstructure name, such as the config file, are only set at runtime and are skipped.
+func viewStruct(v reflect.Value, view map[string]any) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+
+		if strings.Contains(opts, "squash") {
+			viewStruct(v.Field(i), view)
+			continue
+		}
+
+		if len(name) == 0 || name == "-" || v.Field(i).IsZero() {
+			continue
+		}
+
+		if value := viewValue(v.Field(i)); value != nil {
+			view[name] = value
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright © 2018 Anthony Spring <aspring@traackr.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestBinnacleConfigView(t *testing.T) {
+	t.Setenv("BINNACLE_TEST_REPO_USERNAME", "deploy")
+
+	viper.SetConfigFile("../testdata/private-repo.yml")
+	viper.ReadInConfig()
+	c, err := LoadAndValidateFromViper()
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	view := c.View()
+
+	if _, found := view["ConfigFile"]; found {
+		t.Errorf("want the runtime config file to be left out, got %v", view)
+	}
+
+	chart := view["charts"].([]any)[0].(map[string]any)
+	if chart["state"] != StatePresent {
+		t.Errorf("want the defaulted state %q, got %v", StatePresent, chart["state"])
+	}
+	if _, found := chart["values"]; found {
+		t.Errorf("want empty fields to be left out, got %v", chart)
+	}
+
+	repo := view["repositories"].([]any)[0].(map[string]any)
+	username := repo["username"].(map[string]any)
+	if len(username) != 1 || username["env"] != "BINNACLE_TEST_REPO_USERNAME" {
+		t.Errorf("want the username to be given by its reference, got %v", username)
+	}
+	password := repo["password"].(map[string]any)
+	if len(password) != 1 || password["file"] != "secrets/repo-password" {
+		t.Errorf("want the password to be given by its reference, got %v", password)
+	}
+}
+
+func TestBinnacleConfigView_KeepsFalseAndZeroValues(t *testing.T) {
+	c := BinnacleConfig{Charts: []ChartConfig{{
+		Name:   "api",
+		Values: map[string]any{"enabled": false, "replicas": 0, "tag": "", "ports": []any{0, 8080}},
+	}}}
+
+	chart := c.View()["charts"].([]any)[0].(map[string]any)
+	values := chart["values"].(map[string]any)
+
+	if values["enabled"] != false || values["replicas"] != 0 || values["tag"] != "" {
+		t.Errorf("want false, 0 and empty values to be kept, got %v", values)
+	}
+	if ports := values["ports"].([]any); ports[0] != 0 {
+		t.Errorf("want 0 to be kept in lists, got %v", ports)
+	}
+
+	if _, found := chart["version"]; found {
+		t.Errorf("want empty chart fields to be left out, got %v", chart)
+	}
+}